@@ -0,0 +1,75 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import "fmt"
+
+// TB is the subset of testing.TB used by the Assert functions so that
+// this package does not need to import testing.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertStatus reports an error to t unless the req was submitted and
+// received a response with the given status code.
+func AssertStatus(t TB, req *Req, code int) {
+	t.Helper()
+	if req.R == nil {
+		t.Errorf("no response to %v %v", req.M, req.U)
+		return
+	}
+	if req.R.StatusCode != code {
+		t.Errorf("want status %v, got %v", code, req.R.Status)
+	}
+}
+
+// AssertJSONContains reports an error to t unless the data (D) of the
+// submitted req contains everything in want. Maps match if every key
+// in want matches the same key in D (other keys are ignored), slices
+// if they are the same length and every item matches, and anything
+// else if the printed values are the same (so that 1 matches 1.0).
+func AssertJSONContains(t TB, req *Req, want any) {
+	t.Helper()
+	if err := contains(req.D, want, ""); err != nil {
+		t.Errorf("%v", err)
+	}
+}
+
+func contains(got, want any, path string) error {
+	switch w := want.(type) {
+	case map[string]any:
+		g, is := got.(map[string]any)
+		if !is {
+			return fmt.Errorf("%v: want map, got %T", path, got)
+		}
+		for k, v := range w {
+			gv, has := g[k]
+			if !has {
+				return fmt.Errorf("%v.%v: missing", path, k)
+			}
+			if err := contains(gv, v, path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []any:
+		g, is := got.([]any)
+		if !is {
+			return fmt.Errorf("%v: want list, got %T", path, got)
+		}
+		if len(g) != len(w) {
+			return fmt.Errorf("%v: want %v items, got %v", path, len(w), len(g))
+		}
+		for i, v := range w {
+			if err := contains(g[i], v, fmt.Sprintf("%v[%v]", path, i)); err != nil {
+				return err
+			}
+		}
+	default:
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			return fmt.Errorf("%v: want %v, got %v", path, want, got)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+
+	web "github.com/rwxrob/web"
+)
+
+type recorder struct{ errs []string }
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestAssert(t *testing.T) {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"name":"x","tags":["a","b"],"meta":{"n":1,"z":2}}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, D: map[string]any{}}
+	if err := req.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	web.AssertStatus(t, req, 200)
+	web.AssertJSONContains(t, req, map[string]any{
+		"tags": []any{"a", "b"},
+		"meta": map[string]any{"n": 1.0},
+	})
+
+	r := new(recorder)
+	web.AssertStatus(r, req, 404)
+	web.AssertJSONContains(r, req, map[string]any{"meta": map[string]any{"n": 2}})
+	web.AssertJSONContains(r, req, map[string]any{"missing": true})
+	want := []string{
+		"want status 404, got 200 OK",
+		".meta.n: want 2, got 1",
+		".missing: missing",
+	}
+	if fmt.Sprint(r.errs) != fmt.Sprint(want) {
+		t.Errorf("want %q, got %q", want, r.errs)
+	}
+}
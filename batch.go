@@ -0,0 +1,67 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"sync"
+	"time"
+)
+
+// Batch submits several Req concurrently bounded by Max (default 4).
+// When FailFast is true no more requests are started after the first
+// one that fails (those already in flight complete normally). When
+// Every is set no request is started sooner than that after the
+// previous one (rate limiting). When Do is set it is called to submit
+// each req (with its index in reqs) instead of Req.Submit, to submit
+// another Req if the first fails in a particular way, for example.
+type Batch struct {
+	Max      int
+	FailFast bool
+	Every    time.Duration
+	Do       func(i int, req *Req) error
+}
+
+// Submit submits all reqs and returns their errors in the same order.
+// Any req not submitted because of FailFast has a nil error and a nil
+// R (response).
+func (b Batch) Submit(reqs ...*Req) []error {
+	max := b.Max
+	if max < 1 {
+		max = 4
+	}
+	errs := make([]error, len(reqs))
+	sem := make(chan struct{}, max)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed bool
+	for i, req := range reqs {
+		if i > 0 && b.Every > 0 {
+			time.Sleep(b.Every)
+		}
+		sem <- struct{}{}
+		mu.Lock()
+		stop := failed && b.FailFast
+		mu.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int, req *Req) {
+			defer func() { <-sem; wg.Done() }()
+			submit := req.Submit
+			if b.Do != nil {
+				submit = func() error { return b.Do(i, req) }
+			}
+			if err := submit(); err != nil {
+				errs[i] = err
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(i, req)
+	}
+	wg.Wait()
+	return errs
+}
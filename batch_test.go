@@ -0,0 +1,87 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleBatch() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/bad" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintf(w, `{"path":%q}`, r.URL.Path)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	var reqs []*web.Req
+	for _, p := range []string{"/a", "/bad", "/c"} {
+		reqs = append(reqs, &web.Req{U: svr.URL + p, D: map[string]any{}})
+	}
+
+	errs := web.Batch{Max: 2}.Submit(reqs...)
+	for i, req := range reqs {
+		fmt.Println(req.D.(map[string]any)["path"], errs[i])
+	}
+
+	// Output:
+	// /a <nil>
+	// <nil> 500 Internal Server Error
+	// /c <nil>
+}
+
+func ExampleBatch_every() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+
+	reqs := []*web.Req{{U: svr.URL}, {U: svr.URL}, {U: svr.URL}}
+	start := time.Now()
+	web.Batch{Every: 50 * time.Millisecond}.Submit(reqs...)
+	fmt.Println(time.Since(start) >= 100*time.Millisecond)
+
+	// Output:
+	// true
+}
+
+func ExampleBatch_do() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/old" {
+				w.WriteHeader(http.StatusGone)
+				return
+			}
+			fmt.Fprint(w, r.URL.Path)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	reqs := []*web.Req{{U: svr.URL + "/old", D: ``}, {U: svr.URL + "/b", D: ``}}
+	got := make([]*web.Req, len(reqs))
+	batch := web.Batch{Do: func(i int, req *web.Req) error {
+		if err := req.Submit(); req.R == nil || req.R.StatusCode != 410 {
+			got[i] = req
+			return err
+		}
+		got[i] = &web.Req{U: svr.URL + "/new", D: ``}
+		return got[i].Submit()
+	}}
+	errs := batch.Submit(reqs...)
+	for i, req := range got {
+		fmt.Println(req.D, errs[i])
+	}
+
+	// Output:
+	// /new <nil>
+	// /b <nil>
+}
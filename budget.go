@@ -0,0 +1,114 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudget limits the retries of every Req of a Session (see
+// Session.RetryBudget) to Min plus a Ratio of the requests sent within
+// the last Window so that a widespread outage is not made worse by
+// retrying everything (a retry storm). Once it is spent a request that
+// would be retried fails fast instead (as if it were not retryable).
+// It is safe for concurrent use.
+type RetryBudget struct {
+	Ratio  float64       // retries per request sent (default: 0.1)
+	Min    int           // retries within Window regardless of Ratio
+	Window time.Duration // sliding window of counts (default: 10s)
+
+	mu      sync.Mutex
+	buckets [10]budgetBucket
+}
+
+// budgetBucket counts for a tenth of the Window (slot).
+type budgetBucket struct {
+	slot     int64
+	requests int
+	retries  int
+}
+
+// width returns the nanoseconds for each bucket (never less than one).
+func (b *RetryBudget) width() int64 {
+	w := b.Window
+	if w <= 0 {
+		w = 10 * time.Second
+	}
+	if n := int64(w) / int64(len(b.buckets)); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// bucket returns the bucket for now (reset if it was for an older
+// slot).
+func (b *RetryBudget) bucket(now time.Time) *budgetBucket {
+	slot := now.UnixNano() / b.width()
+	k := &b.buckets[slot%int64(len(b.buckets))]
+	if k.slot != slot {
+		*k = budgetBucket{slot: slot}
+	}
+	return k
+}
+
+// counts returns the requests and retries within the Window.
+func (b *RetryBudget) counts(now time.Time) (requests, retries int) {
+	slot := now.UnixNano() / b.width()
+	for _, k := range b.buckets {
+		if slot-k.slot < int64(len(b.buckets)) {
+			requests += k.requests
+			retries += k.retries
+		}
+	}
+	return
+}
+
+// left returns how many more retries are allowed given the counts.
+func (b *RetryBudget) left(requests, retries int) int {
+	ratio := b.Ratio
+	if ratio <= 0 {
+		ratio = 0.1
+	}
+	n := b.Min + int(ratio*float64(requests)) - retries
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// State returns the requests and retries counted within the Window and
+// how many more retries are allowed (for monitoring).
+func (b *RetryBudget) State() (requests, retries, left int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	requests, retries = b.counts(time.Now())
+	return requests, retries, b.left(requests, retries)
+}
+
+// sent counts a request (but not its retries). Nil does nothing.
+func (b *RetryBudget) sent() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.bucket(time.Now()).requests++
+	b.mu.Unlock()
+}
+
+// spend counts a retry and returns true unless none are left. Nil
+// always allows it.
+func (b *RetryBudget) spend() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if b.left(b.counts(now)) == 0 {
+		return false
+	}
+	b.bucket(now).retries++
+	return true
+}
@@ -0,0 +1,49 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleRetryBudget() {
+
+	var hits int32
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	budget := &web.RetryBudget{Min: 1}
+	s := &web.Session{RetryBudget: budget}
+
+	// first retried once, then the budget is spent so no more retries
+	for i := 0; i < 2; i++ {
+		fmt.Println((&web.Req{U: svr.URL, S: s}).Submit())
+	}
+	fmt.Println(atomic.LoadInt32(&hits))
+	fmt.Println(budget.State())
+
+	// Output:
+	// 503 Service Unavailable
+	// 503 Service Unavailable
+	// 3
+	// 2 1 0
+}
+
+func ExampleRetryBudget_State() {
+
+	// a Window of less than ten buckets of one nanosecond still works
+	budget := &web.RetryBudget{Min: 2, Window: time.Nanosecond}
+	fmt.Println(budget.State())
+
+	// Output:
+	// 0 0 2
+}
@@ -0,0 +1,212 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache is where Session.Cached keeps responses by key (see
+// MemoryCache and FileCache) so that other backends (such as Redis or
+// SQLite) can be plugged in. An entry must no longer be returned by Get
+// once its ttl has passed. Implementations must be safe for concurrent
+// use and may drop entries at any time.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry, ttl time.Duration)
+	Delete(key string)
+}
+
+// CacheEntry is a response kept in a Cache.
+type CacheEntry struct {
+	Status  string      `json:"status"`
+	Code    int         `json:"code"`
+	Header  http.Header `json:"header,omitempty"`
+	Trailer http.Header `json:"trailer,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+	Expires time.Time   `json:"expires"` // no longer fresh after
+}
+
+// entry returns the response of the submitted req as a CacheEntry.
+func entry(req *Req, expires time.Time) CacheEntry {
+	return CacheEntry{
+		Status:  req.R.Status,
+		Code:    req.R.StatusCode,
+		Header:  req.R.Header,
+		Trailer: req.Trailer,
+		Body:    req.body,
+		Expires: expires,
+	}
+}
+
+// req returns a Req as if the response of the entry had been submitted
+// (for replay).
+func (e CacheEntry) req() *Req {
+	return &Req{
+		R: &http.Response{
+			Status:     e.Status,
+			StatusCode: e.Code,
+			Header:     e.Header,
+			Trailer:    e.Trailer,
+			Body:       io.NopCloser(bytes.NewReader(e.Body)),
+		},
+		Trailer: e.Trailer,
+		body:    e.Body,
+	}
+}
+
+// flight is a Submit in progress shared by concurrent Cached callers.
+type flight struct {
+	done chan struct{}
+	req  *Req
+	err  error
+}
+
+// Cached submits req with the Session unless an identical request (see
+// Fingerprint) was successfully submitted within ttl in which case the
+// response kept in the Cache (or memory) is decoded into req instead.
+// Concurrent callers of the same request share a single Submit. A
+// response that expired no longer than StaleWhileRevalidate ago is
+// still used but also submitted again in the background (once) to
+// replace it. This is an application level cache that ignores any HTTP
+// caching headers. Only the credentials in H (not those added by
+// Middleware) keep the responses of different users apart (see
+// FingerprintHeaders). It is safe for concurrent use. The Cache is
+// never called while holding the lock of the Session so that a slow one
+// (disk or network) only delays the requests that use it.
+func (s *Session) Cached(ttl time.Duration, req *Req) error {
+	req.S = s
+	key := req.Fingerprint()
+
+	e, hit := s.cache().Get(key)
+	now := time.Now()
+	if hit && now.Before(e.Expires) {
+		return req.replay(e.req())
+	}
+
+	s.mu.Lock()
+	if s.flights == nil {
+		s.flights = map[string]*flight{}
+	}
+	if hit && now.Before(e.Expires.Add(s.StaleWhileRevalidate)) {
+		if _, busy := s.flights[key]; !busy {
+			fresh := *req
+			fresh.D, fresh.OnStatus, fresh.C = nil, nil, nil
+			f := &flight{done: make(chan struct{}), req: &fresh}
+			s.flights[key] = f
+			go s.land(ttl, key, f)
+		}
+		s.mu.Unlock()
+		return req.replay(e.req())
+	}
+	if f, has := s.flights[key]; has {
+		s.mu.Unlock()
+		<-f.done
+		if f.err != nil {
+			return f.err
+		}
+		return req.replay(f.req)
+	}
+	f := &flight{done: make(chan struct{}), req: req}
+	s.flights[key] = f
+	s.mu.Unlock()
+	return s.land(ttl, key, f)
+}
+
+// land submits the req of the flight keeping the response for ttl if
+// successful and then lets any other callers waiting for it know.
+func (s *Session) land(ttl time.Duration, key string, f *flight) error {
+	f.err = f.req.Submit()
+	if f.err == nil {
+		e := entry(f.req, time.Now().Add(ttl))
+		s.cache().Set(key, e, ttl+s.StaleWhileRevalidate)
+	}
+
+	s.mu.Lock()
+	delete(s.flights, key)
+	s.mu.Unlock()
+	close(f.done)
+	return f.err
+}
+
+// cache returns the Cache of the Session or the MemoryCache used
+// without one.
+func (s *Session) cache() Cache {
+	if s.Cache != nil {
+		return s.Cache
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.memory == nil {
+		s.memory = new(MemoryCache)
+	}
+	return s.memory
+}
+
+// replay populates req with the response of the already submitted
+// from Req by decoding its buffered body again.
+func (req *Req) replay(from *Req) error {
+	req.R = from.R
+	req.Trailer = from.Trailer
+	req.body = from.body
+	if len(req.body) == 0 {
+		return nil
+	}
+	return req.decodeStatus(req.R.StatusCode, req.R.Header.Get("Content-Type"), req.body)
+}
+
+// CacheTTL returns how much longer the response (R) is fresh according
+// to its Cache-Control max-age (less any Age) or else its Expires
+// (relative to its Date). Responses that are no-store, no-cache, or
+// private, or that have none of these headers, are not fresh at all
+// (zero).
+func (req *Req) CacheTTL() time.Duration {
+	if req.R == nil {
+		return 0
+	}
+	h := req.R.Header
+	var maxage time.Duration = -1
+	for _, val := range h.Values("Cache-Control") {
+		for _, dir := range strings.Split(val, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(dir), "=")
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache", "private":
+				return 0
+			case "max-age":
+				n, err := strconv.Atoi(strings.Trim(arg, `"`))
+				if err != nil || n < 0 {
+					return 0
+				}
+				maxage = time.Duration(n) * time.Second
+			}
+		}
+	}
+	var ttl time.Duration
+	switch {
+	case maxage >= 0:
+		ttl = maxage
+		if age, err := strconv.Atoi(h.Get("Age")); err == nil && age > 0 {
+			ttl -= time.Duration(age) * time.Second
+		}
+	case h.Get("Expires") != "":
+		expires, err := http.ParseTime(h.Get("Expires"))
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		ttl = expires.Sub(date)
+	}
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
@@ -0,0 +1,200 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleSession_Cached() {
+
+	var mu sync.Mutex
+	var hits int
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits++
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			fmt.Fprintf(w, `{"flag":true}`)
+		}))
+	defer svr.Close()
+
+	s := new(web.Session)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := map[string]any{}
+			if err := s.Cached(time.Minute, &web.Req{U: svr.URL, D: data}); err != nil {
+				fmt.Println(err)
+			}
+			if data["flag"] != true {
+				fmt.Println("missing flag")
+			}
+		}()
+	}
+	wg.Wait()
+
+	data := map[string]any{}
+	s.Cached(time.Minute, &web.Req{U: svr.URL, D: data})
+	fmt.Println(data["flag"], hits)
+
+	// Output:
+	// true 1
+}
+
+func ExampleSession_Cached_noData() {
+
+	var hits int
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			fmt.Fprintf(w, `{"flag":true}`)
+		}))
+	defer svr.Close()
+
+	s := new(web.Session)
+	for i := 0; i < 2; i++ {
+		req := &web.Req{U: svr.URL}
+		fmt.Println(s.Cached(time.Minute, req), req.R.StatusCode)
+	}
+	fmt.Println(hits)
+
+	// Output:
+	// <nil> 200
+	// <nil> 200
+	// 1
+}
+
+func ExampleSession_Cached_staleWhileRevalidate() {
+
+	var mu sync.Mutex
+	var hits int
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits++
+			fmt.Fprintf(w, `{"n":%v}`, hits)
+			mu.Unlock()
+		}))
+	defer svr.Close()
+
+	s := &web.Session{StaleWhileRevalidate: time.Second}
+	get := func() any {
+		data := map[string]any{}
+		if err := s.Cached(200*time.Millisecond, &web.Req{U: svr.URL, D: data}); err != nil {
+			fmt.Println(err)
+		}
+		return data["n"]
+	}
+
+	fmt.Println(get())
+	time.Sleep(250 * time.Millisecond)
+	fmt.Println(get()) // stale but revalidated in the background
+	time.Sleep(50 * time.Millisecond)
+	fmt.Println(get())
+
+	// Output:
+	// 1
+	// 1
+	// 2
+}
+
+func ExampleReq_CacheTTL() {
+
+	ttl := func(header ...string) time.Duration {
+		h := http.Header{}
+		for i := 0; i < len(header); i += 2 {
+			h.Add(header[i], header[i+1])
+		}
+		return (&web.Req{R: &http.Response{Header: h}}).CacheTTL()
+	}
+
+	fmt.Println(ttl("Cache-Control", "public, max-age=300"))
+	fmt.Println(ttl("Cache-Control", "max-age=300", "Age", "100"))
+	fmt.Println(ttl("Cache-Control", "private, max-age=300"))
+	fmt.Println(ttl("Cache-Control", "no-store"))
+	fmt.Println(ttl(
+		"Date", "Mon, 02 Jan 2006 15:04:05 GMT",
+		"Expires", "Mon, 02 Jan 2006 16:04:05 GMT",
+	))
+	fmt.Println(ttl("Expires", "0"))
+	fmt.Println(ttl())
+
+	// Output:
+	// 5m0s
+	// 3m20s
+	// 0s
+	// 0s
+	// 1h0m0s
+	// 0s
+	// 0s
+}
+
+func ExampleFileCache() {
+
+	var hits int
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"hits":%v}`, hits)
+		}))
+	defer svr.Close()
+
+	dir, _ := os.MkdirTemp("", "cache")
+	defer os.RemoveAll(dir)
+	cache := web.FileCache{Dir: filepath.Join(dir, "web")}
+
+	// as if separate runs of a command
+	for i := 0; i < 2; i++ {
+		s := &web.Session{Cache: cache}
+		data := map[string]any{}
+		req := &web.Req{U: svr.URL, D: data}
+		if err := s.Cached(time.Minute, req); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(data["hits"], req.R.StatusCode, req.R.Header.Get("Content-Type"))
+	}
+
+	key := (&web.Req{U: svr.URL}).Fingerprint()
+	_, has := cache.Get(key)
+	fmt.Println(has)
+	cache.Delete(key)
+	_, has = cache.Get(key)
+	fmt.Println(has)
+
+	// Output:
+	// 1 200 application/json
+	// 1 200 application/json
+	// true
+	// false
+}
+
+func ExampleMemoryCache() {
+
+	cache := new(web.MemoryCache)
+	cache.Set("a", web.CacheEntry{Code: 200, Body: []byte("A")}, time.Minute)
+	cache.Set("b", web.CacheEntry{Code: 200}, -time.Second)
+
+	e, has := cache.Get("a")
+	fmt.Println(string(e.Body), has)
+	_, has = cache.Get("b")
+	fmt.Println(has)
+	cache.Delete("a")
+	_, has = cache.Get("a")
+	fmt.Println(has)
+
+	// Output:
+	// A true
+	// false
+	// false
+}
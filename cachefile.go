@@ -0,0 +1,77 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileCache is a Cache kept in the directory (Dir) with one JSON file
+// named after the SHA-256 of the key for every entry so that it
+// persists between runs (of a command, for example). The directory is
+// created when first needed. Entries that cannot be read or written
+// are treated as missing.
+type FileCache struct {
+	Dir string
+}
+
+type fileEntry struct {
+	Key     string     `json:"key"`
+	Deleted time.Time  `json:"deleted"` // after which it has expired
+	Entry   CacheEntry `json:"entry"`
+}
+
+func (c FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get fulfills the Cache interface.
+func (c FileCache) Get(key string) (CacheEntry, bool) {
+	byt, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var f fileEntry
+	if err := json.Unmarshal(byt, &f); err != nil || f.Key != key {
+		return CacheEntry{}, false
+	}
+	if time.Now().After(f.Deleted) {
+		c.Delete(key)
+		return CacheEntry{}, false
+	}
+	return f.Entry, true
+}
+
+// Set fulfills the Cache interface. The file is written to a temporary
+// file first so that no other process ever reads a partial entry.
+func (c FileCache) Set(key string, entry CacheEntry, ttl time.Duration) {
+	byt, err := json.Marshal(fileEntry{key, time.Now().Add(ttl), entry})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".entry.*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(byt)
+	if cerr := tmp.Close(); err != nil || cerr != nil {
+		return
+	}
+	os.Rename(tmp.Name(), c.path(key))
+}
+
+// Delete fulfills the Cache interface.
+func (c FileCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
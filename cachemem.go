@@ -0,0 +1,53 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryCache is a Cache kept in memory that is ready to use as is.
+// Expired entries are removed when next looked up.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	entry   CacheEntry
+	expires time.Time
+}
+
+// Get fulfills the Cache interface.
+func (c *MemoryCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, has := c.entries[key]
+	if !has {
+		return CacheEntry{}, false
+	}
+	if time.Now().After(m.expires) {
+		delete(c.entries, key)
+		return CacheEntry{}, false
+	}
+	return m.entry, true
+}
+
+// Set fulfills the Cache interface.
+func (c *MemoryCache) Set(key string, entry CacheEntry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]memoryEntry{}
+	}
+	c.entries[key] = memoryEntry{entry, time.Now().Add(ttl)}
+}
+
+// Delete fulfills the Cache interface.
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
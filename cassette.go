@@ -0,0 +1,168 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Cassette is an http.RoundTripper that records real responses to
+// a YAML file (Path) the first time a request is seen and replays them
+// from the file every time after that (like VCR). Requests are matched
+// by fingerprint which includes the method, URL, body, and the values
+// of any Headers listed. Set Record to ignore (and overwrite) anything
+// already recorded. Scrub, if set, is called with every new entry
+// before it is saved to remove secrets. The SensitiveHeaders and
+// Set-Cookie are never saved. Use Client to get an http.Client.
+type Cassette struct {
+	Path      string
+	Headers   []string
+	Record    bool
+	Scrub     func(e *CassetteEntry)
+	Transport http.RoundTripper // default: http.DefaultTransport
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]CassetteEntry
+}
+
+// CassetteEntry is a single recorded response of a Cassette.
+type CassetteEntry struct {
+	Key    string              `yaml:"key"`
+	Method string              `yaml:"method"`
+	URL    string              `yaml:"url"`
+	Status int                 `yaml:"status"`
+	Header map[string][]string `yaml:"header,omitempty"`
+	Body   string              `yaml:"body"`
+}
+
+// Client returns an http.Client using the Cassette as its transport.
+func (c *Cassette) Client() *http.Client { return &http.Client{Transport: c} }
+
+// RoundTrip fulfills the http.RoundTripper interface.
+func (c *Cassette) RoundTrip(r *http.Request) (*http.Response, error) {
+	key, err := c.fingerprint(r)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	if e, has := c.entries[key]; has {
+		return e.response(r), nil
+	}
+	t := c.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	res, err := t.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	e := CassetteEntry{
+		Key:    key,
+		Method: r.Method,
+		URL:    r.URL.String(),
+		Status: res.StatusCode,
+		Header: res.Header.Clone(),
+		Body:   string(body),
+	}
+	for _, k := range SensitiveHeaders {
+		delete(e.Header, http.CanonicalHeaderKey(k))
+	}
+	delete(e.Header, "Set-Cookie")
+	if c.Scrub != nil {
+		c.Scrub(&e)
+	}
+	c.entries[key] = e
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, c.save()
+}
+
+// fingerprint returns a hash of everything that identifies r.
+func (c *Cassette) fingerprint(r *http.Request) (string, error) {
+	var body io.Reader
+	if r.Body != nil && r.GetBody != nil {
+		rc, err := r.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+		body = rc
+	}
+	return fingerprint(r.Method, r.URL.String(), r.Header, c.Headers, body)
+}
+
+// load reads the entries from Path once (unless Record).
+func (c *Cassette) load() error {
+	if c.loaded {
+		return nil
+	}
+	c.loaded = true
+	c.entries = map[string]CassetteEntry{}
+	if c.Record {
+		return nil
+	}
+	byt, err := os.ReadFile(c.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var list []CassetteEntry
+	if err := yaml.Unmarshal(byt, &list); err != nil {
+		return err
+	}
+	for _, e := range list {
+		c.entries[e.Key] = e
+	}
+	return nil
+}
+
+// save writes all the entries to Path sorted by key.
+func (c *Cassette) save() error {
+	list := make([]CassetteEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	byt, err := yaml.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.Path, byt, 0600)
+}
+
+func (e CassetteEntry) response(r *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%v %v", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(e.Header).Clone(),
+		Body:          io.NopCloser(strings.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       r,
+	}
+}
@@ -0,0 +1,48 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleCassette() {
+
+	var hits int
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			fmt.Fprintf(w, `{"token":"s3cret","hits":%v}`, hits)
+		}))
+	defer svr.Close()
+
+	dir, _ := os.MkdirTemp("", "web")
+	defer os.RemoveAll(dir)
+
+	c := &web.Cassette{
+		Path: filepath.Join(dir, "api.yaml"),
+		Scrub: func(e *web.CassetteEntry) {
+			e.Body = strings.ReplaceAll(e.Body, "s3cret", "REDACTED")
+		},
+	}
+	defer func(c *http.Client) { web.Client = c }(web.Client)
+	web.Client = c.Client()
+
+	for i := 0; i < 3; i++ {
+		data := map[string]any{}
+		(&web.Req{U: svr.URL, D: data}).Submit()
+		fmt.Println(data["hits"], data["token"])
+	}
+	fmt.Println(hits)
+
+	// Output:
+	// 1 s3cret
+	// 1 REDACTED
+	// 1 REDACTED
+	// 1
+}
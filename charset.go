@@ -0,0 +1,48 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// metaCharset matches the charset of an HTML <meta charset> or
+// <meta http-equiv="Content-Type" content="...; charset=..."> tag.
+var metaCharset = regexp.MustCompile(
+	`(?i)<meta[^>]+charset\s*=\s*["']?\s*([-\w:.]+)`)
+
+// transcode returns the text body (byt) converted to UTF-8 from the
+// charset of the Content-Type (ctype) or, for HTML without one, from the
+// first <meta> charset found in the first 1024 bytes. The body is
+// returned as is if already UTF-8 (or ASCII) or the charset is unknown.
+func transcode(ctype string, byt []byte) []byte {
+	mtype, params, _ := mime.ParseMediaType(ctype)
+	name := params["charset"]
+	if name == "" && mtype == "text/html" {
+		head := byt
+		if len(head) > 1024 {
+			head = head[:1024]
+		}
+		if m := metaCharset.FindSubmatch(head); m != nil {
+			name = string(m[1])
+		}
+	}
+	switch strings.ToLower(name) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return byt
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return byt
+	}
+	out, err := enc.NewDecoder().Bytes(byt)
+	if err != nil {
+		return byt
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_charset() {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+		w.Write([]byte("caf\xe9"))
+	})
+	mux.HandleFunc("/meta", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<meta charset=\"shift_jis\"><p>\x93\xfa\x96\x7b</p>"))
+	})
+	svr := ht.NewServer(mux)
+	defer svr.Close()
+
+	for _, path := range []string{"/latin1", "/meta"} {
+		req := &web.Req{U: svr.URL + path, D: ``}
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(req.D)
+	}
+
+	// Output:
+	// café
+	// <meta charset="shift_jis"><p>日本</p>
+}
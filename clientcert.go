@@ -0,0 +1,78 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+)
+
+// certKey is the client and ClientCert that a client with a client
+// certificate was made from.
+type certKey struct {
+	client *http.Client
+	cert   *tls.Certificate
+}
+
+// certClients are the clients made by withCert (without a Session).
+var certClients = struct {
+	sync.Mutex
+	m map[certKey]*http.Client
+}{m: map[certKey]*http.Client{}}
+
+// withCert returns a copy of the client with a transport that presents
+// the cert, made once and reused for the same client and cert so that
+// its connections are kept alive. The client transport must be an
+// *http.Transport (or nil for the default).
+func withCert(client *http.Client, cert *tls.Certificate) (*http.Client, error) {
+	certClients.Lock()
+	defer certClients.Unlock()
+	key := certKey{client, cert}
+	if c, has := certClients.m[key]; has {
+		return c, nil
+	}
+	var t *http.Transport
+	switch rt := client.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return nil, ReqSyntaxError{"ClientCert requires an *http.Transport (or a Session)"}
+	}
+	present(t, cert)
+	c := *client
+	c.Transport = t
+	certClients.m[key] = &c
+	return &c, nil
+}
+
+// present adds the cert to the TLS client certificates of t.
+func present(t *http.Transport, cert *tls.Certificate) {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+}
+
+// certClient returns the client of the Session but with a transport
+// that presents the cert (see withCert) made once for every cert.
+func (s *Session) certClient(cert *tls.Certificate) *http.Client {
+	base := s.Client()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, has := s.certs[cert]; has {
+		return c
+	}
+	if s.certs == nil {
+		s.certs = map[*tls.Certificate]*http.Client{}
+	}
+	t := s.transport()
+	present(t, cert)
+	c := *base
+	c.Transport = s.roundTripper(t)
+	s.certs[cert] = &c
+	return &c
+}
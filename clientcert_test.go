@@ -0,0 +1,69 @@
+package web_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	ht "net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+// identity returns a self-signed client certificate for the name.
+func identity(name string) *tls.Certificate {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func ExampleReq_ClientCert() {
+
+	var conns int32
+	svr := ht.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+		}))
+	svr.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	svr.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	svr.StartTLS()
+	defer svr.Close()
+
+	defer func(c *http.Client) { web.Client = c }(web.Client)
+	web.Client = svr.Client() // trusts the server certificate
+
+	alice, bob := identity("alice"), identity("bob")
+	for _, cert := range []*tls.Certificate{alice, bob, alice} {
+		req := &web.Req{U: svr.URL, D: ``, ClientCert: cert}
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(req.D)
+	}
+	fmt.Println(atomic.LoadInt32(&conns))
+
+	// Output:
+	// alice
+	// bob
+	// alice
+	// 2
+}
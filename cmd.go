@@ -5,12 +5,32 @@
 package web
 
 import (
+	"encoding/json"
 	"fmt"
+	"html"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/rwxrob/bonzai"
 	Z "github.com/rwxrob/bonzai/z"
 	"github.com/rwxrob/conf"
 	"github.com/rwxrob/help"
+	"github.com/rwxrob/term"
+	"github.com/rwxrob/to"
 	"github.com/rwxrob/vars"
+	"gopkg.in/yaml.v3"
 )
 
 // main branch
@@ -26,7 +46,7 @@ var Cmd = &Z.Cmd{
 
 	Commands: []*Z.Cmd{
 		help.Cmd, conf.Cmd, vars.Cmd, // common
-		get, // post, put, del|delete, patch, dl|download
+		get, head, post, put, curl, check, bench, history, proxy, // del|delete, patch, dl|download
 	},
 
 	Description: `
@@ -35,22 +55,983 @@ var Cmd = &Z.Cmd{
 		even {{exe "curl"}} or {{exe "w3m"}}. In particular, the interface
 		design is purposefully simple and stateful. The high-level {{pre
 		"pkg"}} library can be used independently from the {{cmd .Name}}
-		composable command.`,
+		composable command.
+
+		Settings
+
+		Rather than dashed options, behavior of the {{cmd .Name}}
+		subcommands is changed with cached variables that persist
+		between calls (see {{cmd "var"}}):
+
+		    pager       - command to page long output, "off" to disable
+		    concurrency - most requests in flight at once (default 4)
+		    failfast    - "on" to stop a batch after first failure
+		    rate        - most requests started per second in a batch
+		    keepalive   - "off" for a new connection every request
+		    compressed  - "on" to request any encoding that can be decoded
+		    har         - HAR file to record proxy traffic to
+		    interpolate - "off" to send $NAME references as is
+		    include     - "on" to print response status and headers first
+		    indent      - spaces to indent JSON or YAML output by (such as 2)
+		    render      - "off" to print responses exactly as received
+		    maxbody     - most bytes of any response (default 100MB, 0 none)
+		    timings     - "on" to print how long each phase took to stderr
+		    writeout    - curl --write-out format to print after response
+		    savehttp    - .http file (REST Client) to append each request to
+
+		When interactive (or render is "on") JSON and YAML responses are
+		indented and HTML is shown as text. JSON and HTML are detected
+		from the response itself when the Content-Type is neither.
+
+		Output that is longer than the terminal height is sent to the
+		pager when interactive. The pager defaults to $PAGER and then
+		{{exe "less"}} -R.
+
+		The writeout format may contain the following curl variables
+		(times are in seconds since the start of the request):
+
+		    %{http_code} %{url_effective} %{size_download}
+		    %{time_namelookup} %{time_connect} %{time_appconnect}
+		    %{time_starttransfer} %{time_total}
+
+		Headers
+
+		Headers from the headers map of the configuration are added to
+		every request:
+
+		    headers:
+		      Authorization: Bearer $TOKEN
+
+		Environment
+
+		Any $NAME or ${NAME} in a URL (after bookmark expansion) or
+		configured header value is replaced with the value of the
+		environment variable of that name. References to undefined
+		variables are left as is and $$ is a literal $. Set interpolate to
+		"off" to disable.
+
+		Bookmarks
+
+		Any URL argument may instead be the name of a bookmark from the
+		bookmarks map of the configuration (see {{cmd "conf"}}) which is
+		expanded to the saved URL and completed by name:
+
+		    bookmarks:
+		      api: https://api.example.com/v1
+
+		History
+
+		Every request is recorded in the history cached variable (see
+		{{cmd "history"}}) which keeps the most recent history.max (from
+		configuration, default 20) entries.`,
 }
 
+// branch is Cmd itself, assigned at init() since referring to Cmd
+// from within its own subcommands would be an initialization cycle.
+var branch *Z.Cmd
+
+func init() { branch = Cmd }
+
 var get = &Z.Cmd{
 
 	Name:    `get`,
 	Summary: `submit http get request`,
+	Usage:   `(<url>|@<file>|-)...`,
+	MinArgs: 1,
+	Comp:    bookmarks{},
+
+	Description: `
+		The {{cmd .Name}} command submits a GET request for every URL
+		argument. When more than one is given they are submitted
+		concurrently (no more than the concurrency setting, default 4, at
+		a time) and each result is printed after a ==> URL <== header
+		line in the order given. Set failfast to "on" to stop submitting
+		after the first failure and rate to limit how many are started
+		every second.
+
+		An argument of @file is replaced with every line of the file (or
+		standard input if - is given instead) that is not blank or
+		a comment (#) as URL arguments.`,
+
+	Call: func(x *Z.Cmd, args ...string) error {
+		args, err := targets(args)
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			req := request(session(), `GET`, args[0])
+			return result(req, req.Submit())
+		}
+		reqs := make([]*Req, len(args))
+		s := session()
+		for i, arg := range args {
+			reqs[i] = request(s, `GET`, arg)
+		}
+		batch := Batch{FailFast: setting("failfast") == "on", Every: every()}
+		batch.Max, _ = strconv.Atoi(setting("concurrency"))
+		errs := batch.Submit(reqs...)
+		var out strings.Builder
+		var failed error
+		for i, req := range reqs {
+			if req.R == nil && errs[i] == nil {
+				continue // never submitted
+			}
+			record(req)
+			fmt.Fprintf(&out, "==> %v <==\n", req.U)
+			if errs[i] != nil {
+				failed = errs[i]
+				fmt.Fprintf(&out, "%v\n\n", errs[i])
+				continue
+			}
+			fmt.Fprintf(&out, "%v\n\n", output(req))
+		}
+		if err := page(strings.TrimSpace(out.String())); err != nil {
+			return err
+		}
+		return failed
+	},
+}
+
+var head = &Z.Cmd{
+
+	Name:    `head`,
+	Summary: `submit http head request and print headers`,
+	Usage:   `<url>`,
+	NumArgs: 1,
+	Comp:    bookmarks{},
+
+	Description: `
+		The {{cmd .Name}} command submits a HEAD request and prints only
+		the response status line and headers in the manner of {{exe
+		"curl"}} -I (even when the status is not in the 200s).`,
+
+	Call: func(x *Z.Cmd, args ...string) error {
+		req := request(session(), `HEAD`, args[0])
+		err := req.Submit()
+		record(req)
+		if req.R == nil {
+			return err
+		}
+		return page(strings.TrimSpace(statusHeaders(req.R)))
+	},
+}
+
+var check = &Z.Cmd{
+
+	Name:    `check`,
+	Summary: `check that every URL responds successfully`,
+	Usage:   `(<url>|@<file>|-)...`,
+	MinArgs: 1,
+	Comp:    bookmarks{},
+
+	Description: `
+		The {{cmd .Name}} command submits a HEAD request (or GET if HEAD
+		is not allowed) for every URL (or @file of URLs, see {{cmd "get"}})
+		concurrently (see concurrency and rate) and
+		prints a line for each with OK or FAIL, the response status (0 if
+		none), the response time, and the URL. Anything but a response in the
+		200s is a failure and the command fails if any URL does (making
+		it suitable for smoke tests and cron jobs).`,
+
+	Call: func(x *Z.Cmd, args ...string) error {
+		args, err := targets(args)
+		if err != nil {
+			return err
+		}
+		s := session()
+		heads := make([]*Req, len(args))
+		gets := make([]*Req, len(args))
+		for i, arg := range args {
+			heads[i], gets[i] = request(s, `HEAD`, arg), request(s, `GET`, arg)
+			heads[i].D, gets[i].D = nil, nil
+		}
+		batch := Batch{Every: every()}
+		batch.Max, _ = strconv.Atoi(setting("concurrency"))
+		results, failed := checks(batch, heads, gets)
+		fmt.Println(strings.Join(results, "\n"))
+		if failed > 0 {
+			return fmt.Errorf("%v of %v failed", failed, len(args))
+		}
+		return nil
+	},
+}
+
+// checks submits every HEAD req of heads with the batch (or the GET
+// req of gets at the same index instead when HEAD is not allowed) and
+// returns a result line for each (see check) and how many failed.
+func checks(batch Batch, heads, gets []*Req) ([]string, int) {
+	results := make([]string, len(heads))
+	batch.Do = func(i int, req *Req) error {
+		start := time.Now()
+		err := req.Submit()
+		if req.R != nil && (req.R.StatusCode == 405 || req.R.StatusCode == 501) {
+			req = gets[i]
+			err = req.Submit()
+		}
+		took := time.Since(start).Round(time.Millisecond)
+		result, status := "OK", 0
+		if req.R != nil {
+			status = req.R.StatusCode
+		}
+		if err != nil {
+			result = "FAIL"
+		}
+		results[i] = fmt.Sprintf("%-4v %3v %8v %v", result, status, took, req.U)
+		return err
+	}
+	var failed int
+	for _, err := range batch.Submit(heads...) {
+		if err != nil {
+			failed++
+		}
+	}
+	return results, failed
+}
+
+var bench = &Z.Cmd{
+
+	Name:    `bench`,
+	Summary: `report response times of many requests`,
+	Usage:   `<url> [<count>]`,
 	MinArgs: 1,
 	MaxArgs: 2,
+	Comp:    bookmarks{},
+
+	Description: `
+		The {{cmd .Name}} command submits count (default 100) GET
+		requests for the URL, no more than the concurrency setting at
+		a time, reusing connections (unless keepalive is off), and then
+		reports the number that failed, the throughput, and the 50th,
+		90th, and 99th percentile and maximum response times of those
+		that succeeded (none when all failed).`,
+
+	Call: func(x *Z.Cmd, args ...string) error {
+		count := 100
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid count: %v", args[1])
+			}
+			count = n
+		}
+		s := session()
+		reqs := make([]*Req, count)
+		for i := range reqs {
+			reqs[i] = request(s, `GET`, args[0])
+			reqs[i].D = nil
+			reqs[i].TraceTimings = true
+		}
+		batch := Batch{Every: every()}
+		batch.Max, _ = strconv.Atoi(setting("concurrency"))
+		start := time.Now()
+		errs := batch.Submit(reqs...)
+		took := time.Since(start)
+		var failed int
+		var times []time.Duration // of those that succeeded only
+		for i, req := range reqs {
+			if errs[i] != nil {
+				failed++
+				continue
+			}
+			times = append(times, req.Timings.Total)
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		pct := func(p int) time.Duration { return times[(len(times)-1)*p/100] }
+		fmt.Printf("requests   %v (%v failed)\n", count, failed)
+		fmt.Printf("time       %v\n", took.Round(time.Millisecond))
+		fmt.Printf("throughput %.1f/s\n", float64(count)/took.Seconds())
+		if len(times) > 0 {
+			fmt.Printf("p50 %v p90 %v p99 %v max %v\n", pct(50), pct(90), pct(99),
+				times[len(times)-1])
+		}
+		if failed > 0 {
+			return fmt.Errorf("%v of %v failed", failed, count)
+		}
+		return nil
+	},
+}
+
+var post = &Z.Cmd{
+
+	Name:    `post`,
+	Summary: `submit http post request with form fields`,
+	Usage:   `<url> ([<name>=<value>|<name>:=<json>|<name>@<file>...]|@<file>)`,
+	MinArgs: 1,
+	Comp:    bookmarks{},
+
+	Description: `
+		The {{cmd .Name}} command submits any arguments following the URL
+		as form fields (x-www-form-urlencoded) in the manner of {{exe
+		"curl"}} --data-urlencode. Each value is URL encoded so that no
+		special characters (&, =, spaces) need escaping. A field given as
+		name@file has the contents of the file as its value and one given
+		as name:=json has the JSON (which must be valid) as is as its
+		value. Only the first = or @ separates the name from the value.
+
+		A single @file argument (no name) instead uploads the file as the
+		body with a Content-Type from the file extension (or the first
+		bytes of the file when the extension is unknown) unless one is
+		configured (see Headers). A file ending in .tmpl is first rendered
+		as a text/template with the cached variables of {{cmd "web"}} (see
+		{{cmd "var"}}) so that {{"{{"}}.token}} is replaced with the value
+		of the token variable (body.json.tmpl is sent as JSON).`,
+
+	Call: func(_ *Z.Cmd, args ...string) error { return submitForm(`POST`, args) },
+}
+
+var put = &Z.Cmd{
+
+	Name:    `put`,
+	Summary: `submit http put request with form fields`,
+	Usage:   `<url> ([<name>=<value>|<name>:=<json>|<name>@<file>...]|@<file>)`,
+	MinArgs: 1,
+	Comp:    bookmarks{},
+
+	Description: `
+		The {{cmd .Name}} command is identical to {{cmd "post"}} but uses
+		the PUT method.`,
+
+	Call: func(_ *Z.Cmd, args ...string) error { return submitForm(`PUT`, args) },
+}
+
+var curl = &Z.Cmd{
+
+	Name:    `curl`,
+	Summary: `submit a request from a curl command line`,
+	Usage:   `(<command>|curl <arg>...)`,
+	MinArgs: 1,
+
+	Description: `
+		The {{cmd .Name}} command submits the request described by a curl
+		command line (such as one copied from API documentation) passed
+		either as a single (quoted) argument or as the arguments
+		themselves. Only common curl options are supported (see
+		{{pkg "FromCurl"}}).`,
 
 	Call: func(_ *Z.Cmd, args ...string) error {
-		req := Req{U: args[0], D: ""}
-		if err := req.Submit(); err != nil {
+		var req *Req
+		var err error
+		if len(args) == 1 {
+			req, err = FromCurl(args[0])
+		} else {
+			req, err = fromCurlArgs(args)
+		}
+		if err != nil {
+			return err
+		}
+		req.D, req.S, req.Indent = "", session(), indentation()
+		return result(req, req.Submit())
+	},
+}
+
+// submitForm submits the URL (first arg) with the method and the form
+// fields or body template from the remaining args (see post).
+func submitForm(method string, args []string) error {
+	req := request(session(), method, args[0])
+	if len(args) == 2 && strings.HasPrefix(args[1], "@") {
+		path := args[1][1:]
+		byt, err := os.ReadFile(path)
+		if err != nil {
 			return err
 		}
-		fmt.Println(req.D)
+		if strings.HasSuffix(path, ".tmpl") {
+			path = strings.TrimSuffix(path, ".tmpl")
+			req.BodyTemplate = string(byt)
+			req.Vars = cached()
+		} else {
+			req.B = string(byt)
+		}
+		if !has(req.H, "Content-Type") {
+			req.H["Content-Type"] = sniff(path, byt)
+		}
+	} else {
+		form, err := formFields(args[1:])
+		if err != nil {
+			return err
+		}
+		req.B = form
+	}
+	return result(req, req.Submit())
+}
+
+// sniff returns the content type of the file at path from its extension
+// or, if unknown, from the first bytes of its content (byt).
+func sniff(path string, byt []byte) string {
+	if ctype := mime.TypeByExtension(filepath.Ext(path)); ctype != "" {
+		return ctype
+	}
+	return http.DetectContentType(byt)
+}
+
+// formFields returns the url.Values from name=value, name:=json, and
+// name@file arguments, the first of = or @ deciding which.
+func formFields(args []string) (url.Values, error) {
+	form := url.Values{}
+	for _, arg := range args {
+		i := strings.IndexAny(arg, "=@")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid form field: %v", arg)
+		}
+		name, val := arg[:i], arg[i+1:]
+		switch {
+		case arg[i] == '@':
+			byt, err := os.ReadFile(val)
+			if err != nil {
+				return nil, err
+			}
+			val = string(byt)
+		case strings.HasSuffix(name, ":") && len(name) > 1:
+			if !json.Valid([]byte(val)) {
+				return nil, fmt.Errorf("invalid JSON form field: %v", arg)
+			}
+			name = name[:len(name)-1]
+		}
+		form.Add(name, val)
+	}
+	return form, nil
+}
+
+var proxy = &Z.Cmd{
+
+	Name:    `proxy`,
+	Summary: `start a logging forward proxy`,
+	Usage:   `[<addr>]`,
+	MaxArgs: 1,
+
+	Description: `
+		The {{cmd .Name}} command starts an HTTP forward proxy listening
+		on the given address (default localhost:8080) that logs every
+		request to standard error. HTTPS requests are tunneled as is
+		without being logged. Set har to the path of a HAR file to also record
+		all (non-tunneled) traffic to it.`,
+
+	Call: func(x *Z.Cmd, args ...string) error {
+		addr := "localhost:8080"
+		if len(args) > 0 {
+			addr = args[0]
+		}
+		s := session()
+		s.Middleware = []Middleware{Logging(log.New(os.Stderr, "", log.LstdFlags))}
+		if path := setting("har"); path != "" {
+			if err := s.RecordHAR(path); err != nil {
+				return err
+			}
+		}
+		return Proxy(addr, s)
+	},
+}
+
+var history = &Z.Cmd{
+
+	Name:     `history`,
+	Summary:  `list or rerun recent requests`,
+	Commands: []*Z.Cmd{help.Cmd, rerun},
+	NoArgs:   true,
+
+	Description: `
+		The {{cmd .Name}} command lists the recently submitted requests,
+		most recent first, with the number to pass to {{cmd "rerun"}}
+		followed by the time, method, response status (0 if none), and
+		URL and then * if it cannot be rerun (see {{cmd "rerun"}}).`,
+
+	Call: func(x *Z.Cmd, _ ...string) error {
+		for i, entry := range entries() {
+			fmt.Printf("%3v %v\n", i+1, entry)
+		}
 		return nil
 	},
 }
+
+var rerun = &Z.Cmd{
+
+	Name:    `rerun`,
+	Summary: `submit a request (without a body) from history again`,
+	NumArgs: 1,
+
+	Description: `
+		The {{cmd .Name}} command submits the request of the given
+		history entry number again with the same method and URL (and the
+		headers and environment variables as configured now). Since only
+		those are kept in the history, a request that had a body (form
+		fields or file) or headers of its own (from {{cmd "curl"}}) cannot
+		be rerun and is marked with * instead.`,
+
+	Call: func(x *Z.Cmd, args ...string) error {
+		n, err := strconv.Atoi(args[0])
+		list := entries()
+		if err != nil || n < 1 || n > len(list) {
+			return fmt.Errorf("no history entry: %v", args[0])
+		}
+		method, u, err := parseEntry(list[n-1])
+		if err != nil {
+			return err
+		}
+		req := request(session(), method, u)
+		return result(req, req.Submit())
+	},
+}
+
+// request returns a new Req for the URL or bookmark name with the
+// configured headers and environment variables (see Cmd.Description)
+// interpolated into both.
+func request(s *Session, method, arg string) *Req {
+	u, h := expand(arg), Head{}
+	for k, v := range configured() {
+		h[k] = v
+	}
+	if setting("interpolate") != "off" {
+		u = interpolate(u)
+		for k, v := range h {
+			h[k] = interpolate(v)
+		}
+	}
+	var max int64 = 100 << 20
+	if n, err := strconv.ParseInt(setting("maxbody"), 10, 64); err == nil {
+		max = n
+		if n == 0 {
+			max = -1 // none
+		}
+	}
+	req := &Req{
+		M: method, U: u, H: h, D: "", S: s, Indent: indentation(), MaxBodySize: max,
+		TraceTimings: setting("timings") == "on" || setting("writeout") != "",
+	}
+	typed.Store(req, expand(arg))
+	return req
+}
+
+// indentation returns the indent setting as that many spaces (or an
+// empty string if unset).
+func indentation() string {
+	if n, err := strconv.Atoi(setting("indent")); err == nil && n > 0 {
+		return strings.Repeat(" ", n)
+	}
+	return ""
+}
+
+// typed keeps the URL of every Req from request as typed (after
+// bookmark expansion but before interpolation) so that no value from
+// the environment (a token, for example) is ever saved in the history
+// (see record). The rerun command interpolates it again.
+var typed sync.Map
+
+// envref matches the $$, ${NAME}, and $NAME references of interpolate.
+var envref = regexp.MustCompile(`\$\$|\$\{(\w+)\}|\$(\w+)`)
+
+// interpolate replaces every $NAME or ${NAME} in s with the value of
+// the environment variable of that name and every $$ with a single $.
+// References to undefined variables are left as is so that a literal
+// $ (common in some query strings) is rarely affected.
+func interpolate(s string) string {
+	return envref.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		name := strings.Trim(ref, "${}")
+		if val, has := os.LookupEnv(name); has {
+			return val
+		}
+		return ref
+	})
+}
+
+// result records the submitted req and pages its output (see output)
+// unless err (returned) is not nil. The response status line and
+// headers of a failed req are still printed when including them.
+func result(req *Req, err error) error {
+	record(req)
+	if path := setting("savehttp"); path != "" {
+		if err := saveHTTP(path, req); err != nil {
+			return err
+		}
+	}
+	defer timings(req)
+	if err != nil {
+		if setting("include") == "on" && req.R != nil {
+			fmt.Print(statusHeaders(req.R))
+		}
+		return err
+	}
+	return page(output(req))
+}
+
+// saveHTTP appends the req to the .http file at path (see
+// Req.HTTPFile) separated from any before it by ###.
+func saveHTTP(path string, req *Req) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	text := req.HTTPFile()
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		text = "\n###\n\n" + text
+	}
+	_, err = f.WriteString(text)
+	return err
+}
+
+// timings prints the Timings of the req to standard error when the
+// timings setting is on and the writeout format (if set) to standard
+// output.
+func timings(req *Req) {
+	if !req.TraceTimings {
+		return
+	}
+	if format := setting("writeout"); format != "" {
+		fmt.Print(writeOut(format, req))
+	}
+	if setting("timings") == "on" {
+		t := req.Timings
+		fmt.Fprintf(os.Stderr,
+			"dns %v connect %v tls %v ttfb %v total %v reused %v\n",
+			t.DNS, t.Connect, t.TLS, t.TTFB, t.Total, t.Reused)
+	}
+}
+
+// writeOut returns the format with each curl --write-out style
+// %{variable} replaced (see Cmd.Description) and \n and \t escapes
+// expanded.
+func writeOut(format string, req *Req) string {
+	t := req.Timings
+	secs := func(d time.Duration) string {
+		return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+	}
+	var code, size string = "000", "0"
+	if req.R != nil {
+		code = strconv.Itoa(req.R.StatusCode)
+		n := int64(len(req.body)) // as read, never as decoded or indented
+		if req.body == nil && req.R.ContentLength > 0 {
+			n = req.R.ContentLength
+		}
+		size = strconv.FormatInt(n, 10)
+	}
+	var appconnect time.Duration
+	if t.TLS > 0 {
+		appconnect = t.DNS + t.Connect + t.TLS
+	}
+	return strings.NewReplacer(
+		`%{http_code}`, code,
+		`%{url_effective}`, req.U,
+		`%{size_download}`, size,
+		`%{time_namelookup}`, secs(t.DNS),
+		`%{time_connect}`, secs(t.DNS+t.Connect),
+		`%{time_appconnect}`, secs(appconnect),
+		`%{time_starttransfer}`, secs(t.TTFB),
+		`%{time_total}`, secs(t.Total),
+		`\n`, "\n",
+		`\t`, "\t",
+	).Replace(format)
+}
+
+// output returns the data (D) of the req preceded by the head of the
+// response when the include setting is on.
+func output(req *Req) string {
+	body := fmt.Sprint(req.D)
+	if rendering() && req.R != nil {
+		body = render(req.R.Header.Get("Content-Type"), body, req.Indent)
+	}
+	if setting("include") == "on" {
+		return statusHeaders(req.R) + body
+	}
+	return body
+}
+
+// rendering returns true if the output is to be rendered (see render)
+// based on the render setting or else if interactive.
+func rendering() bool {
+	switch setting("render") {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	return term.IsInteractive()
+}
+
+// render returns the body of a response with the Content-Type (ctype)
+// indented (by indent or else two spaces) if JSON or YAML and as text
+// if HTML. Since responses are often mislabeled, JSON and HTML are also
+// detected from the body itself when the Content-Type is neither.
+func render(ctype, body, indent string) string {
+	kind := syntax(ctype)
+	if kind == "" {
+		switch {
+		case mediatype(ctype) == "text/html":
+			kind = "html"
+		case json.Valid([]byte(body)):
+			kind = "json"
+			ctype = "application/json"
+		case strings.HasPrefix(http.DetectContentType([]byte(body)), "text/html"):
+			kind = "html"
+		}
+	}
+	switch kind {
+	case "json", "yaml":
+		if indent == "" {
+			indent = "  "
+		}
+		return strings.TrimSuffix(
+			string((&Req{Indent: indent}).indent(ctype, []byte(body))), "\n")
+	case "html":
+		return htmlText(body)
+	}
+	return body
+}
+
+var (
+	htmlScript = regexp.MustCompile(`(?is)<script.*?</script\s*>|<style.*?</style\s*>|<!--.*?-->`)
+	htmlBreak  = regexp.MustCompile(`(?i)<br\s*/?>|</?(p|div|h[1-6]|li|tr|table|ul|ol|pre|blockquote|section|article|header|footer|title)(\s[^>]*)?>`)
+	htmlTag    = regexp.MustCompile(`<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlText returns the text of the HTML without any tags, scripts, or
+// styles and with a line for every block (paragraph, heading, etc.).
+func htmlText(s string) string {
+	s = strings.Join(strings.Fields(htmlScript.ReplaceAllString(s, "")), " ")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	s = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// statusHeaders returns the status line and headers of the response in the
+// manner of curl -i followed by a blank line.
+func statusHeaders(res *http.Response) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%v %v\n", res.Proto, res.Status)
+	res.Header.Write(&buf)
+	return strings.ReplaceAll(buf.String(), "\r\n", "\n") + "\n"
+}
+
+// targets returns the args with every @file replaced by the URLs on
+// each line of the file (or standard input for - alone) that is not
+// blank or a comment (#).
+func targets(args []string) ([]string, error) {
+	var list []string
+	for _, arg := range args {
+		var byt []byte
+		var err error
+		switch {
+		case arg == "-":
+			byt, err = io.ReadAll(os.Stdin)
+		case strings.HasPrefix(arg, "@"):
+			byt, err = os.ReadFile(arg[1:])
+		default:
+			list = append(list, arg)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(byt), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				list = append(list, line)
+			}
+		}
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no URLs")
+	}
+	return list, nil
+}
+
+// every returns the time between starting requests from the rate
+// setting (requests per second, none if unset).
+func every() time.Duration {
+	rate, err := strconv.ParseFloat(setting("rate"), 64)
+	if err != nil || rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / rate)
+}
+
+// session returns a Session configured from the settings shared by all
+// the requests of a single command.
+func session() *Session {
+	s := &Session{
+		DisableKeepAlives: setting("keepalive") == "off",
+	}
+	if setting("compressed") == "on" {
+		s.AcceptEncoding = Decodable
+	}
+	return s
+}
+
+// setting returns the cached variable of the given name from the web
+// branch or an empty string if unset.
+func setting(name string) string {
+	val, _ := branch.Get(name)
+	return val
+}
+
+// cached returns all the cached variables of the web branch by name.
+func cached() map[string]string {
+	m := map[string]string{}
+	if Z.Vars == nil {
+		return m
+	}
+	prefix := branch.Path()
+	if prefix != "." {
+		prefix += "."
+	}
+	for _, line := range strings.Split(Z.Vars.Data(), "\n") {
+		k, v, found := strings.Cut(line, "=")
+		if !found || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		m[strings.TrimPrefix(k, prefix)] = to.UnEscReturns(v)
+	}
+	return m
+}
+
+// entries returns the request history, most recent first.
+func entries() []string {
+	list := []string{}
+	lines := strings.Split(setting("history"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i] != "" {
+			list = append(list, lines[i])
+		}
+	}
+	return list
+}
+
+// record adds the submitted req to the history dropping the oldest
+// entries beyond the configured history.max.
+func record(req *Req) {
+	max := 20
+	if v, err := branch.C("history.max"); err == nil {
+		if n, err := strconv.Atoi(v); err == nil {
+			max = n
+		}
+	}
+	lines := strings.Split(setting("history"), "\n")
+	lines = append(lines, historyEntry(req))
+	if len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	branch.Set("history", strings.TrimSpace(strings.Join(lines, "\n")))
+}
+
+// historyEntry returns the history entry of the submitted req: the
+// time, method, response status (0 if none), URL (see typed), and
+// then * if it cannot be rerun (see rerun).
+func historyEntry(req *Req) string {
+	var status int
+	if req.R != nil {
+		status = req.R.StatusCode
+	}
+	u, own := req.U, len(req.H) > 0
+	if v, has := typed.LoadAndDelete(req); has {
+		u, own = v.(string), false // only configured headers
+	}
+	method := strings.ToUpper(req.M)
+	if method == "" {
+		method = `GET`
+	}
+	line := fmt.Sprintf("%v %v %v %v",
+		time.Now().Format(time.RFC3339), method, status, u)
+	if own || req.B != nil || req.BodyTemplate != "" || req.RawBody != nil {
+		line += " *"
+	}
+	return line
+}
+
+// parseEntry returns the method and URL of a history entry (see
+// historyEntry) or an error if it cannot be rerun.
+func parseEntry(line string) (method, u string, err error) {
+	f := strings.Fields(line)
+	switch {
+	case len(f) == 5 && f[4] == "*":
+		return "", "", fmt.Errorf(
+			"cannot rerun %v %v (body and headers are not kept)", f[1], f[3])
+	case len(f) != 4:
+		return "", "", fmt.Errorf("invalid history entry: %v", line)
+	}
+	return f[1], f[3], nil
+}
+
+// saved returns the bookmarks map from the configuration of the web
+// branch (see Cmd.Description).
+func saved() map[string]string {
+	marks := map[string]string{}
+	out, err := branch.C("bookmarks")
+	if err != nil || out == "" || out == "null" {
+		return marks
+	}
+	yaml.Unmarshal([]byte(out), marks)
+	return marks
+}
+
+// configured returns the headers map from the configuration of the web
+// branch (see Cmd.Description).
+func configured() map[string]string {
+	heads := map[string]string{}
+	out, err := branch.C("headers")
+	if err != nil || out == "" || out == "null" {
+		return heads
+	}
+	yaml.Unmarshal([]byte(out), heads)
+	return heads
+}
+
+// expand returns the URL of the bookmark with the given name or the
+// name itself if not a bookmark.
+func expand(name string) string {
+	if u, has := saved()[name]; has {
+		return u
+	}
+	return name
+}
+
+// bookmarks completes the names of saved bookmarks.
+type bookmarks struct{}
+
+// Complete fulfills the bonzai.Completer interface.
+func (bookmarks) Complete(_ bonzai.Command, args ...string) []string {
+	list := []string{}
+	if len(args) > 1 {
+		return list
+	}
+	for name := range saved() {
+		if len(args) == 0 || strings.HasPrefix(name, args[0]) {
+			list = append(list, name)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// page prints out directly unless interactive and out has more lines
+// than the terminal in which case it is piped to the pager (see
+// Cmd.Description).
+func page(out string) error {
+	pager := setting("pager")
+	if pager == "off" || !term.IsInteractive() ||
+		strings.Count(out, "\n") < int(term.WinSize.Row) {
+		fmt.Println(out)
+		return nil
+	}
+	if pager == "" {
+		pager = os.Getenv("PAGER")
+	}
+	if pager == "" {
+		pager = "less -R"
+	}
+	args := strings.Fields(pager)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(out + "\n")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Println(out)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHistoryEntry(t *testing.T) {
+	req := &Req{U: "https://x", R: &http.Response{StatusCode: 200}}
+	method, u, err := parseEntry(historyEntry(req))
+	if err != nil || method != "GET" || u != "https://x" {
+		t.Errorf("got %q %q %v", method, u, err)
+	}
+	if _, _, err := parseEntry("2022-01-01T00:00:00Z  200 https://x"); err == nil {
+		t.Error("no error for entry without method")
+	}
+
+	// only the method and URL are kept so never rerun anything else
+	get := &Req{M: "GET", U: "https://x/secret", H: Head{"A": "configured"}}
+	typed.Store(get, "https://x/$TOKEN")
+	method, u, err = parseEntry(historyEntry(get))
+	if err != nil || method != "GET" || u != "https://x/$TOKEN" {
+		t.Errorf("got %q %q %v", method, u, err)
+	}
+	post := &Req{M: "POST", U: "https://x", B: url.Values{"a": {"1"}}}
+	typed.Store(post, post.U)
+	own := &Req{U: "https://x", H: Head{"X-Own": "1"}} // from curl
+	for _, req := range []*Req{post, own} {
+		line := historyEntry(req)
+		if _, _, err := parseEntry(line); err == nil || !strings.HasSuffix(line, " *") {
+			t.Errorf("no error for %q", line)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("WEB_TOKEN", "secret")
+	os.Unsetenv("WEB_UNDEFINED")
+	for in, want := range map[string]string{
+		"$WEB_TOKEN":              "secret",
+		"${WEB_TOKEN}":            "secret",
+		"/a/${WEB_TOKEN}x":        "/a/secretx",
+		"?t=$WEB_TOKEN&x=1":       "?t=secret&x=1",
+		"$$WEB_TOKEN":             "$WEB_TOKEN",
+		"$$$WEB_TOKEN":            "$secret",
+		"cost$$5":                 "cost$5",
+		"$WEB_UNDEFINED/x":        "$WEB_UNDEFINED/x",
+		"${WEB_UNDEFINED}":        "${WEB_UNDEFINED}",
+		"$ alone":                 "$ alone",
+		"https://x/?q=a$b$$WEB_T": "https://x/?q=a$b$WEB_T",
+	} {
+		if got := interpolate(in); got != want {
+			t.Errorf("interpolate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWriteOut(t *testing.T) {
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"a":[1,2]}`)
+		}))
+	defer svr.Close()
+
+	req := &Req{U: svr.URL, D: "", Indent: "  ", TraceTimings: true}
+	if err := req.Submit(); err != nil {
+		t.Fatal(err)
+	}
+	got := writeOut(`%{http_code} %{size_download} %{url_effective}\n`, req)
+	if want := "200 11 " + svr.URL + "\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, v := range []string{"time_namelookup", "time_connect",
+		"time_appconnect", "time_starttransfer", "time_total"} {
+		got := writeOut("%{"+v+"}", req)
+		if strings.Contains(got, "{") || !strings.Contains(got, ".") {
+			t.Errorf("%v: %q", v, got)
+		}
+	}
+	if got := writeOut(`%{http_code}\t%{size_download}`, &Req{}); got != "000\t0" {
+		t.Errorf("no response: %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, c := range []struct{ ctype, body, want string }{
+		{"application/json", `{"a":1}`, "{\n  \"a\": 1\n}"},
+		{"text/plain", `{"a":[1]}`, "{\n  \"a\": [\n    1\n  ]\n}"},
+		{"", `[1,2]`, "[\n  1,\n  2\n]"},
+		{"application/yaml", "a:   1", "a: 1"},
+		{"", "<!DOCTYPE html><html><p>one</p><p>two &amp; three</p></html>",
+			"one\n\ntwo & three"},
+		{"text/html", "<h1>Title</h1><script>x()</script>text", "Title\ntext"},
+		{"text/plain", "just <b>some</b> text", "just <b>some</b> text"},
+		{"", "just text\n", "just text\n"},
+		{"text/plain", `{"a":`, `{"a":`},
+	} {
+		if got := render(c.ctype, c.body, ""); got != c.want {
+			t.Errorf("render(%q, %q) = %q, want %q", c.ctype, c.body, got, c.want)
+		}
+	}
+	if got := render("", `{"a":1}`, "    "); got != "{\n    \"a\": 1\n}" {
+		t.Errorf("indent: %q", got)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "urls")
+	os.WriteFile(path, []byte("# smoke tests\nhttps://a\n\n  https://b  \n#https://c\n"), 0600)
+
+	got, err := targets([]string{"https://first", "@" + path, "https://last"})
+	want := []string{"https://first", "https://a", "https://b", "https://last"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q %v, want %q", got, err, want)
+	}
+
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	os.Stdin, _ = os.Open(path)
+	defer os.Stdin.Close()
+	if got, err := targets([]string{"-"}); err != nil || !reflect.DeepEqual(got, want[1:3]) {
+		t.Errorf("stdin: got %q %v", got, err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	os.WriteFile(empty, []byte("# none\n\n"), 0600)
+	if _, err := targets([]string{"@" + empty}); err == nil {
+		t.Error("no error without URLs")
+	}
+	if _, err := targets([]string{"@" + filepath.Join(dir, "missing")}); err == nil {
+		t.Error("no error for missing file")
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "note.txt")
+	os.WriteFile(path, []byte("a=b & c@d"), 0600)
+
+	form, err := formFields([]string{
+		"q=a b&c", "eq=x=y", "at=me@example.com", "file@" + path,
+		"tags:=[\"a\",\"b\"]", "n:=1", "q=again", "empty=",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{
+		"q": {"a b&c", "again"}, "eq": {"x=y"}, "at": {"me@example.com"},
+		"file": {"a=b & c@d"}, "tags": {`["a","b"]`}, "n": {"1"}, "empty": {""},
+	}
+	if !reflect.DeepEqual(form, want) {
+		t.Errorf("got %v, want %v", form, want)
+	}
+
+	for _, arg := range []string{"novalue", "=x", "@file", "bad:={", "x@" + filepath.Join(dir, "missing")} {
+		if _, err := formFields([]string{arg}); err == nil {
+			t.Errorf("no error for %q", arg)
+		}
+	}
+}
+
+func TestSniff(t *testing.T) {
+	for _, c := range []struct {
+		path string
+		byt  []byte
+		want string
+	}{
+		{"body.json", []byte(`{}`), "application/json"},
+		{"page.html", []byte("hi"), "text/html; charset=utf-8"},
+		{"body", []byte(`<!DOCTYPE html><html></html>`), "text/html; charset=utf-8"},
+		{"body", []byte("plain"), "text/plain; charset=utf-8"},
+		{"image", []byte("\x89PNG\r\n\x1a\n"), "image/png"},
+		{"data.unknownext", []byte{0, 1, 2}, "application/octet-stream"},
+	} {
+		if got := sniff(c.path, c.byt); got != c.want {
+			t.Errorf("sniff(%q) = %q, want %q", c.path, got, c.want)
+		}
+	}
+}
+
+func TestChecks(t *testing.T) {
+	var gets int32
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/nohead" && r.Method == "HEAD":
+				w.WriteHeader(405)
+			case r.URL.Path == "/nohead":
+				atomic.AddInt32(&gets, 1)
+			case r.URL.Path == "/missing":
+				w.WriteHeader(404)
+			}
+		}))
+	defer svr.Close()
+
+	paths := []string{"/ok", "/nohead", "/missing", "/ok2"}
+	heads, getreqs := make([]*Req, len(paths)), make([]*Req, len(paths))
+	for i, p := range paths {
+		heads[i] = &Req{M: "HEAD", U: svr.URL + p}
+		getreqs[i] = &Req{M: "GET", U: svr.URL + p}
+	}
+	results, failed := checks(Batch{Max: 2}, heads, getreqs)
+	if n := atomic.LoadInt32(&gets); failed != 1 || n != 1 {
+		t.Errorf("failed %v, gets %v", failed, n)
+	}
+	want := []string{"OK 200", "OK 200", "FAIL 404", "OK 200"}
+	for i, line := range results {
+		f := strings.Fields(line)
+		if len(f) != 4 || f[0]+" "+f[1] != want[i] || f[3] != svr.URL+paths[i] {
+			t.Errorf("result %v: %q", i, line)
+		}
+	}
+	if getreqs[1].R == nil || getreqs[0].R != nil {
+		t.Error("GET not only submitted for HEAD not allowed")
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decodable contains the content encodings that are decompressed
+// transparently in the form of an Accept-Encoding header value.
+const Decodable = "gzip, deflate, br, zstd"
+
+// MaxBodySize is the most bytes of a (decompressed) response body that
+// Submit will read (see BodySizeError). Since the limit applies after
+// decompression a tiny compressed response cannot expand without limit
+// (a decompression bomb). This applies to every decompressed response
+// even when streamed (see Req) or drained for an HTTPError. Zero (the
+// default) is no limit.
+var MaxBodySize int64
+
+// BodySizeError is returned when a response body is larger than the
+// MaxBodySize once decompressed.
+type BodySizeError struct {
+	Limit int64
+}
+
+// Error fulfills the error interface.
+func (e BodySizeError) Error() string {
+	return fmt.Sprintf("response body larger than MaxBodySize (%v bytes)", e.Limit)
+}
+
+// maxBody returns the MaxBodySize of the Req or else the package
+// MaxBodySize.
+func (req *Req) maxBody() int64 {
+	if req.MaxBodySize != 0 {
+		return req.MaxBodySize
+	}
+	return MaxBodySize
+}
+
+// readBody reads all of the (decompressed) body r but never more than
+// max bytes (see MaxBodySize).
+func readBody(r io.Reader, max int64) ([]byte, error) {
+	if max <= 0 {
+		return io.ReadAll(r)
+	}
+	byt, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(byt)) > max {
+		return nil, BodySizeError{max}
+	}
+	return byt, nil
+}
+
+// decodable returns true if every encoding in the Accept-Encoding
+// header value (ignoring quality values, identity, and *) is one of
+// those in Decodable.
+func decodable(accept string) bool {
+	for _, enc := range strings.Split(accept, ",") {
+		enc, _, _ = strings.Cut(enc, ";")
+		enc = strings.ToLower(strings.TrimSpace(enc))
+		switch enc {
+		case "", "identity", "*", "x-gzip":
+			continue
+		}
+		known := false
+		for _, d := range strings.Split(Decodable, ",") {
+			if strings.TrimSpace(d) == enc {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return false
+		}
+	}
+	return true
+}
+
+// decompress replaces the response body with one that transparently
+// decodes the Content-Encoding (gzip, deflate, br, zstd), never beyond
+// max bytes (see MaxBodySize), and removes the header. Several
+// encodings (gzip, br) are decoded in reverse of the order listed (the
+// last applied first). A single unrecognized encoding is left as is
+// but one in a list is an EncodingError. Note that net/http only
+// decodes gzip itself, and only when it added the Accept-Encoding
+// header (which it does not when one is set in H). A response that
+// never has a body (to HEAD, 204, and 304) is left as is even with a
+// Content-Encoding.
+func decompress(res *http.Response, max int64) error {
+	switch {
+	case res.Request != nil && res.Request.Method == http.MethodHead,
+		res.StatusCode == http.StatusNoContent,
+		res.StatusCode == http.StatusNotModified:
+		return nil
+	}
+	var encs []string
+	for _, val := range res.Header.Values("Content-Encoding") {
+		for _, enc := range strings.Split(val, ",") {
+			enc = strings.ToLower(strings.TrimSpace(enc))
+			if enc != "" && enc != "identity" {
+				encs = append(encs, enc)
+			}
+		}
+	}
+	if len(encs) == 0 {
+		if res.Uncompressed { // by net/http itself
+			res.Body = decoded{limit(res.Body, max), res.Body}
+		}
+		return nil
+	}
+	r := io.Reader(res.Body)
+	for i := len(encs) - 1; i >= 0; i-- {
+		var err error
+		if r, err = decoder(encs[i], r); err != nil {
+			if e, is := err.(EncodingError); is {
+				if len(encs) == 1 {
+					return nil
+				}
+				e.Encodings = encs
+				err = e
+			}
+			return err
+		}
+	}
+	res.Body = decoded{limit(r, max), res.Body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return nil
+}
+
+// decoder returns a reader that decodes r of the content encoding. An
+// empty r (no gzip or zlib header at all) is an empty body.
+func decoder(enc string, r io.Reader) (io.Reader, error) {
+	switch enc {
+	case "gzip", "x-gzip":
+		d, err := gzip.NewReader(r)
+		if err == io.EOF {
+			return http.NoBody, nil
+		}
+		return d, err
+	case "deflate":
+		d, err := zlib.NewReader(r)
+		if err == io.EOF {
+			return http.NoBody, nil
+		}
+		return d, err
+	case "br":
+		return brotli.NewReader(r), nil
+	case "zstd":
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return nil, EncodingError{Encoding: enc}
+}
+
+// EncodingError is returned when one of several Content-Encodings of
+// a response (Encodings) is not Decodable.
+type EncodingError struct {
+	Encoding  string
+	Encodings []string
+}
+
+// Error fulfills the error interface.
+func (e EncodingError) Error() string {
+	return fmt.Sprintf("unsupported Content-Encoding: %v (of %v)",
+		e.Encoding, strings.Join(e.Encodings, ", "))
+}
+
+// limit returns r but with a BodySizeError once more than max bytes
+// have been read from it (r returned as is if no max).
+func limit(r io.Reader, max int64) io.Reader {
+	if max <= 0 {
+		return r
+	}
+	return &limited{r: r, left: max, max: max}
+}
+
+// limited is the reader of a decompressed body (see limit).
+type limited struct {
+	r    io.Reader
+	left int64
+	max  int64
+}
+
+// Read fulfills the io.Reader interface.
+func (l *limited) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, BodySizeError{l.max}
+	}
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n + int(l.left), BodySizeError{l.max}
+	}
+	return n, err
+}
+
+// decoded reads from the decoder but closes the original body.
+type decoded struct {
+	io.Reader
+	body io.Closer
+}
+
+// Close fulfills the io.Closer interface.
+func (d decoded) Close() error { return d.body.Close() }
+
+// compress returns buf compressed with the given encoding (gzip or
+// zstd) for use as a request body with the same Content-Encoding.
+func compress(enc, buf string) (string, error) {
+	var out bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch strings.ToLower(enc) {
+	case "gzip":
+		w = gzip.NewWriter(&out)
+	case "zstd":
+		w, err = zstd.NewWriter(&out)
+	default:
+		return "", ReqSyntaxError{"unsupported compression: " + enc}
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, buf); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,223 @@
+package web_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_brotli() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			bw := brotli.NewWriter(w)
+			fmt.Fprintf(bw, `{"get":"WORKED"}`)
+			bw.Close()
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, D: data, H: web.Head{"Accept-Encoding": "br"}}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["get"])
+
+	// Output:
+	// WORKED
+}
+
+func ExampleSession_acceptEncoding() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"accept":%q}`, r.Header.Get("Accept-Encoding"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	s := &web.Session{AcceptEncoding: web.Decodable}
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, D: data, S: s}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["accept"])
+
+	req = &web.Req{U: svr.URL, S: &web.Session{AcceptEncoding: "compress"}}
+	fmt.Println(req.Submit())
+
+	req = &web.Req{U: svr.URL, S: &web.Session{AcceptEncoding: "gz"}}
+	fmt.Println(req.Submit())
+
+	// Output:
+	// gzip, deflate, br, zstd
+	// undecodable Accept-Encoding: compress
+	// undecodable Accept-Encoding: gz
+}
+
+func ExampleReq_Submit_zstd() {
+
+	// decompress request body and respond with it compressed
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			d, _ := zstd.NewReader(r.Body)
+			in, _ := io.ReadAll(d)
+			w.Header().Set("Content-Encoding", "zstd")
+			zw, _ := zstd.NewWriter(w)
+			zw.Write(in)
+			zw.Close()
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+	req := &web.Req{
+		M:        `POST`,
+		U:        svr.URL,
+		B:        `{"echo":"WORKED"}`,
+		D:        data,
+		H:        web.Head{"Accept-Encoding": "zstd"},
+		Compress: "zstd",
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["echo"])
+
+	// Output:
+	// WORKED
+}
+
+func ExampleMaxBodySize() {
+
+	// echoes the request body back gzip encoded
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Content-Type", "application/json")
+			zw := gzip.NewWriter(w)
+			io.Copy(zw, r.Body)
+			zw.Close()
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	defer func(n int64) { web.MaxBodySize = n }(web.MaxBodySize)
+	web.MaxBodySize = 1 << 10
+
+	data := map[string]any{}
+	req := &web.Req{M: `POST`, U: svr.URL, B: `{"echo":"back"}`, D: data}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["echo"])
+
+	// compresses to about a kilobyte but expands to a megabyte
+	bomb := `"` + strings.Repeat("0", 1<<20) + `"`
+	req = &web.Req{M: `POST`, U: svr.URL, B: bomb, D: ``}
+	fmt.Println(req.Submit())
+
+	// Output:
+	// back
+	// response body larger than MaxBodySize (1024 bytes)
+}
+
+func ExampleMaxBodySize_stream() {
+
+	// endless stream of highly compressible lines
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			line := strings.Repeat("0", 99) + "\n"
+			for r.Context().Err() == nil {
+				if _, err := io.WriteString(zw, line); err != nil {
+					return
+				}
+			}
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	defer func(n int64) { web.MaxBodySize = n }(web.MaxBodySize)
+	web.MaxBodySize = 1 << 20
+
+	var count int
+	req := &web.Req{U: svr.URL, D: func(string) error { count++; return nil }}
+	fmt.Println(req.Submit(), count > 10000)
+
+	// Output:
+	// response body larger than MaxBodySize (1048576 bytes) true
+}
+
+func ExampleReq_Submit_multipleEncodings() {
+
+	// gzip first and then br (as listed)
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", r.URL.Query().Get("enc"))
+			bw := brotli.NewWriter(w)
+			zw := gzip.NewWriter(bw)
+			io.WriteString(zw, "twice")
+			zw.Close()
+			bw.Close()
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL + "?enc=gzip,+br", D: ``}
+	fmt.Println(req.Submit(), req.D)
+
+	req = &web.Req{U: svr.URL + "?enc=gzip,+compress", D: ``}
+	fmt.Println(req.Submit())
+
+	// Output:
+	// <nil> twice
+	// unsupported Content-Encoding: compress (of gzip, compress)
+}
+
+func ExampleReq_Submit_maxBodySize() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, strings.Repeat("x", 100))
+		}))
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, D: ``, MaxBodySize: 10}
+	fmt.Println(req.Submit(), web.MaxBodySize)
+
+	// Output:
+	// response body larger than MaxBodySize (10 bytes) 0
+}
+
+func ExampleReq_Submit_emptyGzip() {
+
+	// some servers (and CDNs) label even an empty body as gzip
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	h := web.Head{"Accept-Encoding": "gzip"}
+	for _, m := range []string{"HEAD", "GET"} {
+		req := &web.Req{M: m, U: svr.URL, H: h, D: ``}
+		fmt.Printf("%v %v %q\n", m, req.Submit(), req.D)
+	}
+
+	// Output:
+	// HEAD <nil> ""
+	// GET <nil> ""
+}
@@ -0,0 +1,215 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// csvField is a struct field and the CSV column name for it (the csv
+// tag or else the field name).
+type csvField struct {
+	name  string
+	index int
+}
+
+// csvFields returns the columns of the exported fields of the struct
+// type in order (skipping any tagged csv:"-").
+func csvFields(t reflect.Type) []csvField {
+	var fields []csvField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("csv"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields = append(fields, csvField{name, i})
+	}
+	return fields
+}
+
+// csvTarget returns the element type of data if it can be decoded from
+// CSV (a pointer to a slice, see csvSlice).
+func csvTarget(data any) (reflect.Type, bool) {
+	t := reflect.TypeOf(data)
+	if t == nil || t.Kind() != reflect.Pointer || !csvSlice(t.Elem()) {
+		return nil, false
+	}
+	return t.Elem().Elem(), true
+}
+
+// csvSlice returns true if the type is a slice of []string, of structs,
+// or of pointers to structs (each element being a CSV record).
+func csvSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	elem := t.Elem()
+	switch {
+	case elem == reflect.TypeOf([]string{}):
+		return true
+	case elem.Kind() == reflect.Struct:
+		return true
+	case elem.Kind() == reflect.Pointer && elem.Elem().Kind() == reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// decodeCSV populates data (see csvTarget) from the CSV byt separated
+// by comma (default ','). Every record of a [][]string is kept as is
+// but the first record for structs is the header with the name of the
+// column for each field (see csvFields, matched ignoring case). Columns
+// without a field are ignored.
+func decodeCSV(byt []byte, data any, comma rune) error {
+	elem, _ := csvTarget(data)
+	r := csv.NewReader(bytes.NewReader(byt))
+	if comma != 0 {
+		r.Comma = comma
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+	slice := reflect.ValueOf(data).Elem()
+	slice.SetLen(0)
+	if elem.Kind() == reflect.Slice {
+		for _, rec := range records {
+			slice.Set(reflect.Append(slice, reflect.ValueOf(rec)))
+		}
+		return nil
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	st := elem
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+	}
+	column := map[int]int{} // record column to field index
+	for _, f := range csvFields(st) {
+		for i, name := range records[0] {
+			if strings.EqualFold(strings.TrimSpace(name), f.name) {
+				column[i] = f.index
+			}
+		}
+	}
+	for line, rec := range records[1:] {
+		v := reflect.New(st).Elem()
+		for i, val := range rec {
+			field, has := column[i]
+			if !has || val == "" {
+				continue
+			}
+			if err := setCSV(v.Field(field), val); err != nil {
+				return fmt.Errorf("csv line %v, column %q: %w", line+2, records[0][i], err)
+			}
+		}
+		if elem.Kind() == reflect.Pointer {
+			v = v.Addr()
+		}
+		slice.Set(reflect.Append(slice, v))
+	}
+	return nil
+}
+
+// setCSV sets the field to the CSV value converted to its type.
+func setCSV(field reflect.Value, val string) error {
+	if u, is := field.Addr().Interface().(encoding.TextUnmarshaler); is {
+		return u.UnmarshalText([]byte(val))
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type: %v", field.Type())
+	}
+	return nil
+}
+
+// encodeCSV returns the data (see csvSlice) as CSV separated by comma
+// (default ','). A [][]string is written as is but structs are preceded
+// by a header record with the column for each field (see csvFields).
+func encodeCSV(data any, comma rune) ([]byte, error) {
+	rv := reflect.ValueOf(data)
+	if !rv.IsValid() || !csvSlice(rv.Type()) {
+		return nil, fmt.Errorf("cannot encode as CSV: %T", data)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if comma != 0 {
+		w.Comma = comma
+	}
+	if records, is := data.([][]string); is {
+		w.WriteAll(records)
+		return buf.Bytes(), w.Error()
+	}
+	st := rv.Type().Elem()
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+	}
+	fields := csvFields(st)
+	rec := make([]string, len(fields))
+	for i, f := range fields {
+		rec[i] = f.name
+	}
+	w.Write(rec)
+	for i := 0; i < rv.Len(); i++ {
+		v := reflect.Indirect(rv.Index(i))
+		for n, f := range fields {
+			rec[n] = ""
+			if v.IsValid() {
+				rec[n] = csvValue(v.Field(f.index))
+			}
+		}
+		w.Write(rec)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvValue returns the field as the text of a CSV value.
+func csvValue(field reflect.Value) string {
+	if m, is := field.Interface().(encoding.TextMarshaler); is {
+		byt, _ := m.MarshalText()
+		return string(byt)
+	}
+	return fmt.Sprint(field.Interface())
+}
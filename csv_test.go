@@ -0,0 +1,82 @@
+package web_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_csv() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			if r.URL.Query().Has("semi") {
+				fmt.Fprint(w, "a;b\n1;\"x;y\"\n")
+				return
+			}
+			fmt.Fprint(w, "id,name,unknown,price\n1,\"Doe, Jane\",?,9.5\n2,\"say \"\"hi\"\"\",,0\n")
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type Item struct {
+		ID    int
+		Name  string  `csv:"name"`
+		Price float64 `csv:"price"`
+	}
+	var items []Item
+	fmt.Println((&web.Req{U: svr.URL, D: &items}).Submit())
+	fmt.Printf("%+v\n", items)
+
+	var records [][]string
+	req := &web.Req{U: svr.URL + "?semi", D: &records, Comma: ';'}
+	fmt.Println(req.Submit(), records)
+
+	// Output:
+	// <nil>
+	// [{ID:1 Name:Doe, Jane Price:9.5} {ID:2 Name:say "hi" Price:0}]
+	// <nil> [[a b] [1 x;y]]
+}
+
+func ExampleReq_Submit_csvBody() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			fmt.Printf("%v\n%s", r.Header.Get("Content-Type"), body)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type Item struct {
+		ID    int
+		Name  string  `csv:"name"`
+		Price float64 `csv:"price"`
+		Note  string  `csv:"-"`
+	}
+	items := []Item{{1, "Doe, Jane", 9.5, "skip"}, {2, `say "hi"`, 0, ""}}
+	(&web.Req{M: "POST", U: svr.URL, B: items}).Submit()
+
+	records := [][]string{{"a", "b"}, {"1", "x;y"}}
+	(&web.Req{M: "POST", U: svr.URL, B: records, Comma: ';'}).Submit()
+
+	// any other Content-Type is kept (and JSON encoded)
+	h := web.Head{"content-type": "application/json"}
+	(&web.Req{M: "POST", U: svr.URL, B: items[1:], H: h}).Submit()
+	fmt.Println()
+
+	// Output:
+	// text/csv
+	// ID,name,price
+	// 1,"Doe, Jane",9.5
+	// 2,"say ""hi""",0
+	// text/csv
+	// a;b
+	// 1;"x;y"
+	// application/json
+	// [{"ID":2,"Name":"say \"hi\"","Price":0,"Note":""}]
+}
@@ -0,0 +1,279 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"encoding/base64"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// FromCurl returns a new Req from a curl command line (such as those
+// copied from API documentation) which may span several lines ending in
+// a backslash and use single or double quotes as in a POSIX shell. The
+// leading curl is optional. Only the following curl options are
+// supported:
+//
+//     -X, --request <method>
+//     -H, --header <name: value>
+//     -d, --data, --data-ascii, --data-binary <data|@file>
+//         --data-raw <data>
+//         --data-urlencode <name=value|name@file|value>
+//     -u, --user <user:password>
+//     -A, --user-agent <agent>
+//     -e, --referer <url>
+//     -b, --cookie <name=value>
+//     -G, --get
+//     -I, --head
+//         --url <url>
+//         --compressed
+//
+// The -s, -S, -L, -v, -i, -f, -g options (and their long forms) are
+// ignored since they only affect the output of curl or are already the
+// behavior of Submit. Any other option is a ReqSyntaxError. As with curl,
+// data makes the default method POST with a Content-Type of
+// application/x-www-form-urlencoded (unless given) and several are
+// joined with &.
+func FromCurl(cmd string) (*Req, error) {
+	args, err := shellWords(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return fromCurlArgs(args)
+}
+
+// curlArgOpts are the curl options that take an argument by long name
+// with their short name (if any).
+var curlArgOpts = map[string]string{
+	"request": "X", "header": "H", "data": "d", "data-ascii": "",
+	"data-binary": "", "data-raw": "", "data-urlencode": "", "user": "u",
+	"user-agent": "A", "referer": "e", "cookie": "b", "url": "",
+}
+
+// curlFlags are the curl options without an argument that are either
+// supported or ignored.
+var curlFlags = map[string]string{
+	"get": "G", "head": "I", "compressed": "", "silent": "s",
+	"show-error": "S", "location": "L", "verbose": "v", "include": "i",
+	"fail": "f", "globoff": "g",
+}
+
+// fromCurlArgs returns a Req from the already split words of a curl
+// command line (see FromCurl).
+func fromCurlArgs(args []string) (*Req, error) {
+	if len(args) > 0 && args[0] == "curl" {
+		args = args[1:]
+	}
+	short := map[string]string{}
+	for long, s := range curlArgOpts {
+		if s != "" {
+			short[s] = long
+		}
+	}
+	for long, s := range curlFlags {
+		if s != "" {
+			short[s] = long
+		}
+	}
+
+	req := &Req{H: Head{}}
+	var data []string
+	var get, head bool
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var opts []string // long names
+		var val string
+		var hasVal bool
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			name := arg[2:]
+			if n, v, found := strings.Cut(name, "="); found {
+				name, val, hasVal = n, v, true
+			}
+			opts = []string{name}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for n, c := range arg[1:] {
+				long, has := short[string(c)]
+				if !has {
+					return nil, ReqSyntaxError{"unsupported curl option: -" + string(c)}
+				}
+				opts = append(opts, long)
+				if _, takes := curlArgOpts[long]; takes && n+2 < len(arg) {
+					val, hasVal = arg[n+2:], true
+					break
+				}
+			}
+		default:
+			if req.U != "" {
+				return nil, ReqSyntaxError{"more than one curl URL: " + arg}
+			}
+			req.U = arg
+			continue
+		}
+
+		for _, opt := range opts {
+			if _, takes := curlArgOpts[opt]; takes && !hasVal {
+				if i+1 >= len(args) {
+					return nil, ReqSyntaxError{"missing curl argument for " + arg}
+				}
+				i++
+				val, hasVal = args[i], true
+			}
+			switch opt {
+			case "request":
+				req.M = strings.ToUpper(val)
+			case "header":
+				name, v, _ := strings.Cut(val, ":")
+				req.H[strings.TrimSpace(name)] = strings.TrimSpace(v)
+			case "data", "data-ascii", "data-binary":
+				if strings.HasPrefix(val, "@") {
+					byt, err := os.ReadFile(val[1:])
+					if err != nil {
+						return nil, err
+					}
+					val = string(byt)
+					if opt != "data-binary" {
+						val = strings.NewReplacer("\r", "", "\n", "").Replace(val)
+					}
+				}
+				data = append(data, val)
+			case "data-raw":
+				data = append(data, val)
+			case "data-urlencode":
+				enc, err := urlencode(val)
+				if err != nil {
+					return nil, err
+				}
+				data = append(data, enc)
+			case "user":
+				req.H["Authorization"] = "Basic " +
+					base64.StdEncoding.EncodeToString([]byte(val))
+			case "user-agent":
+				req.H["User-Agent"] = val
+			case "referer":
+				req.H["Referer"] = val
+			case "cookie":
+				req.H["Cookie"] = val
+			case "url":
+				req.U = val
+			case "get":
+				get = true
+			case "head":
+				head = true
+			case "compressed":
+				req.H["Accept-Encoding"] = Decodable
+			default:
+				if _, ignored := curlFlags[opt]; !ignored {
+					return nil, ReqSyntaxError{"unsupported curl option: " + arg}
+				}
+			}
+		}
+	}
+
+	if req.U == "" {
+		return nil, ReqSyntaxError{"missing curl URL"}
+	}
+	if !strings.Contains(req.U, "://") {
+		req.U = "http://" + req.U // like curl
+	}
+	switch {
+	case head:
+		req.M = "HEAD"
+	case get && len(data) > 0:
+		sep := "?"
+		if strings.Contains(req.U, "?") {
+			sep = "&"
+		}
+		req.U += sep + strings.Join(data, "&")
+	case len(data) > 0:
+		req.B = strings.Join(data, "&")
+		if req.M == "" {
+			req.M = "POST"
+		}
+		if !has(req.H, "Content-Type") {
+			req.H["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	}
+	return req, nil
+}
+
+// urlencode returns the curl --data-urlencode value encoded.
+func urlencode(val string) (string, error) {
+	i := strings.IndexAny(val, "=@")
+	switch {
+	case i < 0:
+		return url.QueryEscape(val), nil
+	case val[i] == '=':
+		return val[:i+1] + url.QueryEscape(val[i+1:]), nil
+	}
+	byt, err := os.ReadFile(val[i+1:])
+	if err != nil {
+		return "", err
+	}
+	enc := url.QueryEscape(string(byt))
+	if i == 0 {
+		return enc, nil
+	}
+	return val[:i] + "=" + enc, nil
+}
+
+// shellWords splits the command line into words in the manner of
+// a POSIX shell (without any expansion).
+func shellWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	var inword bool
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(line) {
+				i++
+				if line[i] != '\n' {
+					word.WriteByte(line[i])
+					inword = true
+				}
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, ReqSyntaxError{"unterminated single quote"}
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inword = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) &&
+					strings.IndexByte("\"\\$`\n", line[i+1]) >= 0 {
+					i++
+					if line[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, ReqSyntaxError{"unterminated double quote"}
+			}
+			inword = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inword {
+				words = append(words, word.String())
+				word.Reset()
+				inword = false
+			}
+		default:
+			word.WriteByte(c)
+			inword = true
+		}
+	}
+	if inword {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,52 @@
+package web_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleFromCurl() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			byt, _ := io.ReadAll(r.Body)
+			user, pass, _ := r.BasicAuth()
+			fmt.Fprintf(w, "%v %v %v:%v %v %s",
+				r.Method, r.Header.Get("Content-Type"), user, pass,
+				r.Header.Get("X-Trace"), byt)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req, err := web.FromCurl(`curl -sS -X PUT '` + svr.URL + `/items' \
+		-H "Content-Type: application/json" -H 'X-Trace: "a b"' \
+		-u me:secret \
+		--data-raw '{"name": "it'\''s"}'`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	req.D = ``
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	req, _ = web.FromCurl(`curl ` + svr.URL + ` --data-urlencode 'q=a b&c' -d x=1`)
+	req.D = ``
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	_, err = web.FromCurl(`curl -k https://example.com`)
+	fmt.Println(err)
+
+	// Output:
+	// PUT application/json me:secret "a b" {"name": "it's"}
+	// POST application/x-www-form-urlencoded :  q=a+b%26c&x=1
+	// unsupported curl option: -k
+}
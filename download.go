@@ -0,0 +1,76 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Download submits req with the Session and writes the response body
+// (as it arrives) to the file at path. The body is written to a
+// temporary file in the same directory first which is only renamed to
+// path once complete so that path is never left partially downloaded.
+// Concurrent callers downloading the same request (see Fingerprint)
+// to the same path share a single download and all get its result
+// (see Cached and FingerprintHeaders). D is ignored. It is safe for
+// concurrent use.
+func (s *Session) Download(req *Req, path string) error {
+	req.S = s
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	key := req.Fingerprint() + " > " + path
+
+	s.mu.Lock()
+	if s.flights == nil {
+		s.flights = map[string]*flight{}
+	}
+	if f, has := s.flights[key]; has {
+		s.mu.Unlock()
+		<-f.done
+		if f.err == nil {
+			req.R = f.req.R
+		}
+		return f.err
+	}
+	f := &flight{done: make(chan struct{}), req: req}
+	s.flights[key] = f
+	s.mu.Unlock()
+
+	f.err = req.download(path)
+
+	s.mu.Lock()
+	delete(s.flights, key)
+	s.mu.Unlock()
+	close(f.done)
+	return f.err
+}
+
+// download submits req writing the body into a temporary file that is
+// renamed to path when done (and removed otherwise).
+func (req *Req) download(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	data := req.D
+	req.D = tmp
+	err = req.Submit()
+	req.D = data
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,51 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleSession_Download() {
+
+	var count int32
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&count, 1)
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, "artifact data")
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	dir, _ := os.MkdirTemp("", "download")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "artifact")
+
+	s := new(web.Session)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Download(&web.Req{U: svr.URL}, path); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	byt, _ := os.ReadFile(path)
+	entries, _ := os.ReadDir(dir)
+	fmt.Println(atomic.LoadInt32(&count), string(byt), len(entries))
+
+	// Output:
+	// 1 artifact data 1
+}
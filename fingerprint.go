@@ -0,0 +1,113 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// FingerprintHeaders are the only request headers that make a
+// difference to the Fingerprint of a Req. The credentials
+// (Authorization and Cookie) are included so that requests of different
+// users never share a cached (see Session.Cached) or downloaded (see
+// Session.Download) response, only ever as part of the hash. Remove
+// them only when every user gets the same response. Add others that
+// select a different response (such as Accept-Language).
+var FingerprintHeaders = []string{"Accept", "Authorization", "Content-Type", "Cookie"}
+
+// Fingerprint returns a hash of everything that identifies the request
+// as the same as another: the method, the URL (normalized), the
+// FingerprintHeaders, and the body. The scheme and host of the URL are
+// lowercase, default ports are dropped, the query is sorted, and any
+// fragment is left out. A body that is an io.Reader is left out since
+// it cannot be read without being consumed. Session.Cached and Download
+// use it as their keys.
+func (req *Req) Fingerprint() string {
+	method := strings.ToUpper(req.M)
+	if method == "" {
+		method = `GET`
+	}
+	u, err := req.target()
+	if err != nil {
+		u = req.U
+	}
+
+	head := Head{}
+	for k, v := range req.H {
+		head[k] = v
+	}
+	var body io.Reader
+	switch v := req.B.(type) {
+	case Multipart:
+		var parts strings.Builder
+		for _, p := range v {
+			fmt.Fprintf(&parts, "%q %q %q %q\n", p.Name, p.FileName, p.Path, p.Value)
+		}
+		body = strings.NewReader(parts.String())
+	case io.Reader:
+	default:
+		if req.RawBody != nil {
+			body = strings.NewReader(string(req.RawBody))
+			break
+		}
+		buf, _ := req.encode(head)
+		body = strings.NewReader(buf)
+	}
+
+	header := http.Header{}
+	for k, v := range head {
+		header.Add(k, v)
+	}
+	sum, _ := fingerprint(method, normalURL(u), header, FingerprintHeaders, body)
+	return sum
+}
+
+// fingerprint returns the hex SHA-256 of the method, URL, values of the
+// named headers (in order by name), and body (if any).
+func fingerprint(
+	method, u string, header http.Header, names []string, body io.Reader,
+) (string, error) {
+	h := sha256.New()
+	fmt.Fprintln(h, method, u)
+	names = append([]string{}, names...)
+	sort.Strings(names)
+	for _, k := range names {
+		fmt.Fprintln(h, http.CanonicalHeaderKey(k), header.Values(k))
+	}
+	if body != nil {
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// normalURL returns the URL u normalized for a Fingerprint (or as is if
+// it cannot be parsed).
+func normalURL(u string) string {
+	p, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	p.Scheme = strings.ToLower(p.Scheme)
+	p.Host = strings.ToLower(p.Host)
+	switch {
+	case p.Scheme == "http" && strings.HasSuffix(p.Host, ":80"):
+		p.Host = strings.TrimSuffix(p.Host, ":80")
+	case p.Scheme == "https" && strings.HasSuffix(p.Host, ":443"):
+		p.Host = strings.TrimSuffix(p.Host, ":443")
+	}
+	if p.Path == "" {
+		p.Path = "/"
+	}
+	p.RawQuery = p.Query().Encode()
+	p.Fragment, p.RawFragment = "", ""
+	return p.String()
+}
@@ -0,0 +1,44 @@
+package web_test
+
+import (
+	"fmt"
+	"net/url"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Fingerprint() {
+
+	a := &web.Req{
+		U: "HTTPS://Example.com:443/users?b=2&a=1#top",
+		H: web.Head{"Authorization": "Bearer one"},
+	}
+	b := &web.Req{
+		M: "get",
+		U: "https://example.com/users",
+		Q: url.Values{"a": {"1"}, "b": {"2"}},
+		H: web.Head{"Authorization": "Bearer two"},
+	}
+	fmt.Println(a.Fingerprint() == b.Fingerprint())
+
+	// same but for the credentials
+	b.H = a.H
+	fmt.Println(a.Fingerprint() == b.Fingerprint())
+
+	// different body
+	c := &web.Req{M: "POST", U: "https://example.com/users", B: "one"}
+	d := &web.Req{M: "POST", U: "https://example.com/users", B: "two"}
+	fmt.Println(c.Fingerprint() == d.Fingerprint())
+
+	// only when included
+	defer func(h []string) { web.FingerprintHeaders = h }(web.FingerprintHeaders)
+	web.FingerprintHeaders = []string{"Accept", "Content-Type"}
+	b.H = web.Head{"Authorization": "Bearer two"}
+	fmt.Println(a.Fingerprint() == b.Fingerprint())
+
+	// Output:
+	// false
+	// true
+	// false
+	// true
+}
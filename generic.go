@@ -0,0 +1,58 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+)
+
+// Get submits a GET request for the URL (with the query added) and
+// returns the response decoded into a T as if a pointer to it were the
+// D of a Req (see Req). A string T is the body as text. Use a Req for
+// anything more.
+func Get[T any](u string, query url.Values) (T, error) {
+	return submit[T](&Req{U: u, Q: query})
+}
+
+// Post submits a POST request for the URL with the body and returns
+// the response decoded the same as Get. A body that is a struct, map,
+// or slice (other than those handled by B, see Req, such as NDJSON or
+// the [][]string or []T of structs sent as text/csv) is sent as JSON.
+func Post[T any](u string, body any) (T, error) {
+	req := &Req{U: u, M: "POST", B: body}
+	switch rv := reflect.ValueOf(body); reflect.Indirect(rv).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		switch body.(type) {
+		case url.Values, GraphQL, Multipart, NDJSON, []byte:
+		default:
+			if csvSlice(rv.Type()) {
+				break // text/csv
+			}
+			byt, err := json.Marshal(body)
+			if err != nil {
+				var zero T
+				return zero, err
+			}
+			req.B = string(byt)
+			req.H = Head{"Content-Type": "application/json"}
+		}
+	}
+	return submit[T](req)
+}
+
+// submit submits the req decoding into a new T.
+func submit[T any](req *Req) (T, error) {
+	var data T
+	if p, is := any(&data).(*string); is {
+		req.D = ""
+		err := req.Submit()
+		*p = req.D.(string)
+		return data, err
+	}
+	req.D = &data
+	err := req.Submit()
+	return data, err
+}
@@ -0,0 +1,71 @@
+package web_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleGet() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `[{"name":%q,"user_id":1}]`, r.URL.Query().Get("name"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type User struct {
+		Name string `json:"name"`
+		ID   int    `json:"user_id"`
+	}
+	users, err := web.Get[[]User](svr.URL, url.Values{"name": {"doe"}})
+	fmt.Println(users, err)
+
+	text, err := web.Get[string](svr.URL, nil)
+	fmt.Println(text, err)
+
+	// Output:
+	// [{doe 1}] <nil>
+	// [{"name":"","user_id":1}] <nil>
+}
+
+func ExamplePost() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var in map[string]any
+			byt, _ := io.ReadAll(r.Body)
+			json.Unmarshal(byt, &in)
+			fmt.Fprintf(w, `{"type":%q,"greeting":"hello %v"}`,
+				r.Header.Get("Content-Type"), in["name"])
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type Greeting struct {
+		Type     string
+		Greeting string
+	}
+	out, err := web.Post[Greeting](svr.URL, struct {
+		Name string `json:"name"`
+	}{"doe"})
+	fmt.Printf("%+v %v\n", out, err)
+
+	out, err = web.Post[Greeting](svr.URL, web.NDJSON{map[string]any{"name": "doe"}})
+	fmt.Printf("%+v %v\n", out, err)
+
+	out, err = web.Post[Greeting](svr.URL, [][]string{{"name"}, {"doe"}})
+	fmt.Printf("%+v %v\n", out, err)
+
+	// Output:
+	// {Type:application/json Greeting:hello doe} <nil>
+	// {Type:application/x-ndjson Greeting:hello doe} <nil>
+	// {Type:text/csv Greeting:hello <nil>} <nil>
+}
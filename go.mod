@@ -3,11 +3,20 @@ module github.com/rwxrob/web
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/andybalholm/brotli v1.0.4
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/klauspost/compress v1.15.15
 	github.com/rwxrob/bonzai v0.14.1
 	github.com/rwxrob/conf v0.8.0
 	github.com/rwxrob/help v0.5.0
 	github.com/rwxrob/json v0.8.0
+	github.com/rwxrob/term v0.2.7
+	github.com/rwxrob/to v0.7.0
 	github.com/rwxrob/vars v0.4.2
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	golang.org/x/text v0.3.7
 	gopkg.in/yaml.v3 v3.0.0
 )
 
@@ -28,16 +37,15 @@ require (
 	github.com/rwxrob/fs v0.5.2 // indirect
 	github.com/rwxrob/scan v0.9.0 // indirect
 	github.com/rwxrob/structs v0.6.0 // indirect
-	github.com/rwxrob/term v0.2.7 // indirect
-	github.com/rwxrob/to v0.7.0 // indirect
 	github.com/rwxrob/yq v0.3.0 // indirect
 	github.com/timtadh/data-structures v0.5.3 // indirect
 	github.com/timtadh/lexmachine v0.2.2 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e // indirect
 	golang.org/x/net v0.0.0-20220524220425-1d687d428aca // indirect
 	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
 	golang.org/x/term v0.0.0-20220526004731-065cf7ba2467 // indirect
-	golang.org/x/text v0.3.7 // indirect
 	golang.org/x/xerrors v0.0.0-20220517211312-f3a8303e98df // indirect
 	gopkg.in/op/go-logging.v1 v1.0.0-20160211212156-b2cb9fa56473 // indirect
 )
@@ -0,0 +1,58 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// GraphQL is a body (B) that is JSON encoded into the standard GraphQL
+// request envelope (with Content-Type application/json). It is usually
+// submitted with the POST method and a GraphQLResult as data (D).
+type GraphQL struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// GraphQLResult is a data (D) type for the standard GraphQL response
+// envelope. The data portion is JSON decoded into Data, which should be
+// assigned a pointer to the caller's own type before submitting. Any
+// Errors are also returned by Submit.
+type GraphQLResult struct {
+	Data   any           `json:"data"`
+	Errors GraphQLErrors `json:"errors,omitempty"`
+}
+
+// GraphQLError is a single error from a GraphQLResult.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLErrors are the errors from a GraphQLResult.
+type GraphQLErrors []GraphQLError
+
+// Error fulfills the error interface by joining the messages.
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Message
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// decode populates the result from the response returning the Errors,
+// if any.
+func (r *GraphQLResult) decode(byt []byte) error {
+	if err := json.Unmarshal(byt, r); err != nil {
+		return err
+	}
+	if len(r.Errors) > 0 {
+		return r.Errors
+	}
+	return nil
+}
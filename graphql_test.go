@@ -0,0 +1,54 @@
+package web_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleGraphQL() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var q web.GraphQL
+			json.NewDecoder(r.Body).Decode(&q)
+			if q.Variables["id"] == "bad" {
+				fmt.Fprint(w, `{"data":null,"errors":[{"message":"not found"}]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":{"user":{"name":%q}}}`, q.Variables["id"])
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	var out struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+
+	req := &web.Req{
+		M: `POST`,
+		U: svr.URL,
+		B: web.GraphQL{
+			Query:     `query($id: ID!) { user(id: $id) { name } }`,
+			Variables: map[string]any{"id": "rwxrob"},
+		},
+		D: &web.GraphQLResult{Data: &out},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(out.User.Name)
+
+	req.B = web.GraphQL{Query: `{}`, Variables: map[string]any{"id": "bad"}}
+	req.D = &web.GraphQLResult{}
+	fmt.Println(req.Submit())
+
+	// Output:
+	// rwxrob
+	// graphql: not found
+}
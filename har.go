@@ -0,0 +1,301 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HAR is an HTTP Archive (version 1.2) as exported by the developer
+// tools of most browsers. Only the fields used by this package are
+// included.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the log of an HAR.
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator identifies the application that created the HAR.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a single request and response of an HAR.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+// HARRequest is the request of an HAREntry.
+type HARRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []HARPair    `json:"cookies"`
+	Headers     []HARPair    `json:"headers"`
+	QueryString []HARPair    `json:"queryString"`
+	PostData    *HARPostData `json:"postData,omitempty"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+// HARResponse is the response of an HAREntry.
+type HARResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	Cookies     []HARPair  `json:"cookies"`
+	Headers     []HARPair  `json:"headers"`
+	Content     HARContent `json:"content"`
+	RedirectURL string     `json:"redirectURL"`
+	HeadersSize int        `json:"headersSize"`
+	BodySize    int        `json:"bodySize"`
+}
+
+// HARPair is a name and value (header, query parameter, cookie).
+type HARPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a HARRequest.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is the (decompressed) body of a HARResponse. A body that
+// is not text (valid UTF-8) is base64 encoded in Text with an Encoding
+// of base64 (so that nothing is lost).
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// harContent returns the HARContent of the body (see HARContent).
+func harContent(ctype string, body []byte) HARContent {
+	c := HARContent{Size: len(body), MimeType: ctype, Text: string(body)}
+	if !utf8.Valid(body) {
+		c.Text = base64.StdEncoding.EncodeToString(body)
+		c.Encoding = "base64"
+	}
+	return c
+}
+
+// HARTimings are the durations in milliseconds of the phases of an
+// HAREntry. Since only the total is known it is all considered wait.
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harRecorder keeps the HAR of a Session and the file it is saved to.
+type harRecorder struct {
+	sync.Mutex
+	path string
+	har  HAR
+}
+
+// RecordHAR records every request submitted with the Session (and its
+// response) into the HAR file at path, which is created if needed or
+// appended to if it already exists. The entire file is written again
+// after each request so that it is always complete.
+func (s *Session) RecordHAR(path string) error {
+	rec := &harRecorder{path: path}
+	byt, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		rec.har.Log = HARLog{
+			Version: "1.2",
+			Creator: HARCreator{"github.com/rwxrob/web", "v0.5.0"},
+			Entries: []HAREntry{},
+		}
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(byt, &rec.har); err != nil {
+			return err
+		}
+	}
+	s.har = rec
+	return nil
+}
+
+// add appends an HAREntry for the request with body (before any
+// encoding) and response res with the (decompressed) body resbody
+// then saves the HAR.
+func (rec *harRecorder) add(
+	start time.Time, r *http.Request, body string,
+	res *http.Response, resbody []byte,
+) error {
+	ms := float64(time.Since(start)) / float64(time.Millisecond)
+	e := HAREntry{
+		StartedDateTime: start,
+		Time:            ms,
+		Request: HARRequest{
+			Method:      r.Method,
+			URL:         r.URL.String(),
+			HTTPVersion: r.Proto,
+			Cookies:     []HARPair{},
+			Headers:     harPairs(r.Header),
+			QueryString: harPairs(r.URL.Query()),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Response: HARResponse{
+			Status:      res.StatusCode,
+			StatusText:  http.StatusText(res.StatusCode),
+			HTTPVersion: res.Proto,
+			Cookies:     []HARPair{},
+			Headers:     harPairs(res.Header),
+			Content:     harContent(res.Header.Get("Content-Type"), resbody),
+			RedirectURL: res.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: HARTimings{Send: 0, Wait: ms, Receive: 0},
+	}
+	if body != "" {
+		e.Request.PostData = &HARPostData{r.Header.Get("Content-Type"), body}
+	}
+	rec.Lock()
+	defer rec.Unlock()
+	rec.har.Log.Entries = append(rec.har.Log.Entries, e)
+	byt, err := json.MarshalIndent(rec.har, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(rec.path, byt, 0600)
+}
+
+// harPairs returns the sorted name and value pairs of a header or
+// url.Values (which share the same underlying type).
+func harPairs(m map[string][]string) []HARPair {
+	pairs := []HARPair{}
+	for k, vals := range m {
+		for _, v := range vals {
+			pairs = append(pairs, HARPair{k, v})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// HARMissError is returned by an HARClient when no entry of the HAR
+// matches the request and there is no fallback.
+type HARMissError struct {
+	Method string
+	URL    string
+}
+
+// Error fulfills the error interface.
+func (e HARMissError) Error() string {
+	return fmt.Sprintf("no HAR entry for %v %v", e.Method, e.URL)
+}
+
+// HARClient returns an http.Client that responds to requests from the
+// entries of the HAR file at path (see RecordHAR) instead of the
+// network, for deterministic tests that are recorded once and replayed
+// forever. Requests are matched by method and URL. When several
+// entries match they are replayed in order with the last repeated once
+// they run out. Unmatched requests are sent with the fallback transport
+// (such as http.DefaultTransport) or, if nil, fail with HARMissError.
+// Assign the client to Client or use it directly.
+func HARClient(path string, fallback http.RoundTripper) (*http.Client, error) {
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var har HAR
+	if err := json.Unmarshal(byt, &har); err != nil {
+		return nil, err
+	}
+	rt := &harReplay{
+		entries:  map[string][]HAREntry{},
+		next:     map[string]int{},
+		fallback: fallback,
+	}
+	for _, e := range har.Log.Entries {
+		k := e.Request.Method + " " + e.Request.URL
+		rt.entries[k] = append(rt.entries[k], e)
+	}
+	return &http.Client{Transport: rt}, nil
+}
+
+type harReplay struct {
+	sync.Mutex
+	entries  map[string][]HAREntry
+	next     map[string]int
+	fallback http.RoundTripper
+}
+
+// RoundTrip fulfills the http.RoundTripper interface.
+func (rt *harReplay) RoundTrip(r *http.Request) (*http.Response, error) {
+	k := r.Method + " " + r.URL.String()
+	rt.Lock()
+	list := rt.entries[k]
+	if len(list) == 0 {
+		rt.Unlock()
+		if rt.fallback != nil {
+			return rt.fallback.RoundTrip(r)
+		}
+		return nil, HARMissError{r.Method, r.URL.String()}
+	}
+	i := rt.next[k]
+	if i < len(list)-1 {
+		rt.next[k]++
+	}
+	rt.Unlock()
+	e := list[i].Response
+	body := []byte(e.Content.Text)
+	if e.Content.Encoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(e.Content.Text); err != nil {
+			return nil, err
+		}
+	}
+	res := &http.Response{
+		Status:        fmt.Sprintf("%v %v", e.Status, e.StatusText),
+		StatusCode:    e.Status,
+		Proto:         e.HTTPVersion,
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+	res.ProtoMajor, res.ProtoMinor, _ = http.ParseHTTPVersion(e.HTTPVersion)
+	for _, h := range e.Headers {
+		res.Header.Add(h.Name, h.Value)
+	}
+	// content text is decoded so the recorded length no longer applies
+	res.Header.Del("Content-Encoding")
+	res.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return res, nil
+}
@@ -0,0 +1,172 @@
+package web_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleSession_RecordHAR() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"path":%q}`, r.URL.Path)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	dir, _ := os.MkdirTemp("", "web")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.har")
+
+	s := new(web.Session)
+	if err := s.RecordHAR(path); err != nil {
+		fmt.Println(err)
+	}
+	for _, p := range []string{"/one", "/two"} {
+		req := &web.Req{U: svr.URL + p, D: map[string]any{}, S: s}
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	// streamed responses are recorded without content
+	req := &web.Req{U: svr.URL + "/three", D: io.Discard, S: s}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+
+	var har web.HAR
+	byt, _ := os.ReadFile(path)
+	json.Unmarshal(byt, &har)
+	for _, e := range har.Log.Entries {
+		fmt.Printf("%v %v %q\n", e.Request.Method, e.Response.Status, e.Response.Content.Text)
+	}
+
+	// Output:
+	// GET 200 "{\"path\":\"/one\"}"
+	// GET 200 "{\"path\":\"/two\"}"
+	// GET 200 ""
+}
+
+func ExampleHARClient() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"live":true}`)
+		}))
+
+	dir, _ := os.MkdirTemp("", "web")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.har")
+
+	// record once
+	s := new(web.Session)
+	s.RecordHAR(path)
+	(&web.Req{U: svr.URL, D: map[string]any{}, S: s}).Submit()
+	svr.Close()
+
+	// replay forever (with the server gone)
+	client, err := web.HARClient(path, nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	defer func(c *http.Client) { web.Client = c }(web.Client)
+	web.Client = client
+
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, D: data}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["live"])
+
+	req = &web.Req{U: svr.URL + "/other"}
+	fmt.Println(req.Submit() != nil)
+
+	// Output:
+	// true
+	// true
+}
+
+func ExampleHARContent() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G', 0xff, 0x00})
+		}))
+	defer svr.Close()
+
+	dir, _ := os.MkdirTemp("", "web")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "binary.har")
+
+	s := new(web.Session)
+	s.RecordHAR(path)
+	(&web.Req{U: svr.URL, D: ``, S: s}).Submit()
+
+	var har web.HAR
+	byt, _ := os.ReadFile(path)
+	json.Unmarshal(byt, &har)
+	c := har.Log.Entries[0].Response.Content
+	fmt.Println(c.Size, c.Encoding, c.Text)
+
+	// Output:
+	// 6 base64 iVBOR/8A
+}
+
+func ExampleHARClient_browser() {
+
+	// as exported by a browser (headers of the compressed response)
+	har := web.HAR{Log: web.HARLog{Version: "1.2", Entries: []web.HAREntry{
+		{
+			Request: web.HARRequest{Method: "GET", URL: "https://example.com/data"},
+			Response: web.HARResponse{
+				Status: 200, StatusText: "OK", HTTPVersion: "HTTP/1.1",
+				Headers: []web.HARPair{
+					{Name: "Content-Encoding", Value: "gzip"},
+					{Name: "Content-Length", Value: "31"},
+				},
+				Content: web.HARContent{Text: `{"live":true}`},
+			},
+		},
+		{
+			Request: web.HARRequest{Method: "GET", URL: "https://example.com/image"},
+			Response: web.HARResponse{
+				Status: 200, StatusText: "OK", HTTPVersion: "HTTP/1.1",
+				Content: web.HARContent{Text: "iVBOR/8A", Encoding: "base64"},
+			},
+		},
+	}}}
+
+	dir, _ := os.MkdirTemp("", "web")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "browser.har")
+	byt, _ := json.Marshal(har)
+	os.WriteFile(path, byt, 0600)
+
+	client, err := web.HARClient(path, nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	defer func(c *http.Client) { web.Client = c }(web.Client)
+	web.Client = client
+
+	req := &web.Req{U: "https://example.com/data", D: ``}
+	fmt.Println(req.Submit(), req.D, req.R.Header.Get("Content-Length"))
+
+	req = &web.Req{U: "https://example.com/image", D: ``}
+	fmt.Printf("%v %q\n", req.Submit(), req.D)
+
+	// Output:
+	// <nil> {"live":true} 13
+	// <nil> "\x89PNG\xff\x00"
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+)
+
+// http10 configures the transport to send HTTP/1.0 requests (see
+// Session.ForceHTTP10). Since net/http always writes an HTTP/1.1
+// request line, every connection is wrapped to change it as it is
+// written (above TLS for https, so that the request line can still be
+// found, see tlsState).
+func http10(t *http.Transport) {
+	t.DisableKeepAlives = true
+	t.ForceAttemptHTTP2 = false
+	t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	dial := t.DialContext
+	t.DialContext = func(c context.Context, nw, addr string) (net.Conn, error) {
+		conn, err := dial(c, nw, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &conn10{Conn: conn}, nil
+	}
+	t.DialTLSContext = func(c context.Context, nw, addr string) (net.Conn, error) {
+		conn, err := dial(c, nw, addr)
+		if err != nil {
+			return nil, err
+		}
+		config := &tls.Config{}
+		if t.TLSClientConfig != nil {
+			config = t.TLSClientConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tconn := tls.Client(conn, config)
+		if err := tconn.HandshakeContext(c); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return &conn10{Conn: tconn}, nil
+	}
+}
+
+// conn10 changes the version of the first request line written to it
+// from HTTP/1.1 to HTTP/1.0.
+type conn10 struct {
+	net.Conn
+	written bool
+}
+
+// Write fulfills the io.Writer interface.
+func (c *conn10) Write(b []byte) (int, error) {
+	if c.written {
+		return c.Conn.Write(b)
+	}
+	c.written = true
+	end := bytes.IndexByte(b, '\n')
+	i := bytes.Index(b, []byte(" HTTP/1.1\r\n"))
+	if i < 0 || i > end {
+		return c.Conn.Write(b)
+	}
+	line := append([]byte{}, b...)
+	line[i+8] = '0'
+	return c.Conn.Write(line)
+}
+
+// tlsState returns the transport but setting the TLS of every response
+// from a TLS connection wrapped by http10, which net/http leaves nil
+// since the connection is no longer a *tls.Conn.
+func tlsState(t http.RoundTripper) http.RoundTripper {
+	return RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var conn net.Conn
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) { conn = info.Conn },
+		}
+		res, err := t.RoundTrip(r.WithContext(httptrace.WithClientTrace(r.Context(), trace)))
+		if res == nil || res.TLS != nil {
+			return res, err
+		}
+		if c, is := conn.(*conn10); is {
+			if tconn, is := c.Conn.(*tls.Conn); is {
+				state := tconn.ConnectionState()
+				res.TLS = &state
+			}
+		}
+		return res, err
+	})
+}
+
+// buffer reads a request body of unknown length (streamed, which would
+// be sent with chunked encoding) into memory so that it is sent with
+// a Content-Length instead since HTTP/1.0 has no chunked encoding.
+func buffer(r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength > 0 {
+		return nil
+	}
+	byt, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+	r.ContentLength = int64(len(byt))
+	r.Body = http.NoBody
+	if len(byt) > 0 {
+		r.Body = io.NopCloser(bytes.NewReader(byt))
+	}
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(byt)), nil
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// HTTPFile returns the request in the .http (or .rest) file format of
+// editor extensions such as REST Client so that it can be saved and
+// sent again from there: the request line, the headers (including any
+// implied by B), a blank line, and the body. A body that is an *os.File
+// is referred to by name (< path) and any other io.Reader is left out
+// since it cannot be read without being consumed. The body is never
+// compressed.
+func (req *Req) HTTPFile() string {
+	var out strings.Builder
+
+	method := req.M
+	if method == "" {
+		method = `GET`
+	}
+	u, err := req.target()
+	if err != nil {
+		u = req.U
+	}
+	out.WriteString(strings.ToUpper(method) + " " + u + " HTTP/1.1\n")
+
+	head := Head{}
+	for k, v := range req.H {
+		head[k] = v
+	}
+	if req.Host != "" {
+		head["Host"] = req.Host
+	}
+
+	var body string
+	switch v := req.B.(type) {
+	case nil:
+		if req.RawBody != nil {
+			body = string(req.RawBody)
+			break
+		}
+		body, _ = req.encode(head)
+	case *os.File:
+		body = "< " + v.Name()
+	case Multipart:
+		body = v.httpFile(head)
+	case io.Reader:
+	default:
+		body, _ = req.encode(head)
+	}
+
+	keys := make([]string, 0, len(head))
+	for k := range head {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out.WriteString(k + ": " + head[k] + "\n")
+	}
+
+	if body != "" {
+		out.WriteString("\n" + body)
+		if !strings.HasSuffix(body, "\n") {
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+// httpFile returns the Multipart in the .http file format (see
+// HTTPFile) referring to each file by name (< path) and leaving out
+// any other Body.
+func (m Multipart) httpFile(head Head) string {
+	body := m.body()
+	head["Content-Type"] = body.contentType()
+	var out strings.Builder
+	for _, p := range m {
+		out.WriteString("--" + body.boundary + "\n")
+		h := p.header()
+		for _, k := range []string{"Content-Disposition", "Content-Type"} {
+			if v := h.Get(k); v != "" {
+				out.WriteString(k + ": " + v + "\n")
+			}
+		}
+		out.WriteString("\n")
+		switch {
+		case p.Path != "":
+			out.WriteString("< " + p.Path + "\n")
+		case p.Body == nil:
+			out.WriteString(p.Value + "\n")
+		}
+	}
+	out.WriteString("--" + body.boundary + "--\n")
+	return out.String()
+}
@@ -0,0 +1,31 @@
+package web_test
+
+import (
+	"fmt"
+	"net/url"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_HTTPFile() {
+
+	req := &web.Req{
+		M: "post",
+		U: "https://api.example.com/users",
+		Q: url.Values{"notify": {"true"}},
+		H: web.Head{"Authorization": "Bearer token"},
+		B: url.Values{"name": {"doe"}, "role": {"admin"}},
+	}
+	fmt.Print(req.HTTPFile())
+
+	req = &web.Req{U: "https://api.example.com/users/1"}
+	fmt.Print(req.HTTPFile())
+
+	// Output:
+	// POST https://api.example.com/users?notify=true HTTP/1.1
+	// Authorization: Bearer token
+	// Content-Type: application/x-www-form-urlencoded
+	//
+	// name=doe&role=admin
+	// GET https://api.example.com/users/1 HTTP/1.1
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// syntax returns json or yaml for a Content-Type of either (or empty).
+func syntax(ctype string) string {
+	mt := mediatype(ctype)
+	switch {
+	case mt == "application/json", strings.HasSuffix(mt, "+json"):
+		return "json"
+	case mt == "application/yaml", mt == "application/x-yaml",
+		mt == "text/yaml", strings.HasSuffix(mt, "+yaml"):
+		return "yaml"
+	}
+	return ""
+}
+
+// indents returns true if a response of the Content-Type (ctype) is
+// to be indented (see Indent).
+func (req *Req) indents(ctype string) bool {
+	return req.Indent != "" && syntax(ctype) != ""
+}
+
+// indent returns the JSON or YAML byt indented by Indent or as is if
+// it is neither or cannot be parsed.
+func (req *Req) indent(ctype string, byt []byte) []byte {
+	if !req.indents(ctype) {
+		return byt
+	}
+	var out bytes.Buffer
+	switch syntax(ctype) {
+	case "json":
+		if err := json.Indent(&out, bytes.TrimSpace(byt), "", req.Indent); err != nil {
+			return byt
+		}
+		out.WriteByte('\n')
+	case "yaml":
+		var node yaml.Node
+		if err := yaml.Unmarshal(byt, &node); err != nil {
+			return byt
+		}
+		enc := yaml.NewEncoder(&out)
+		enc.SetIndent(len(req.Indent))
+		if err := enc.Encode(&node); err != nil {
+			return byt
+		}
+		enc.Close()
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,50 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_indent() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/yaml" {
+				w.Header().Set("Content-Type", "application/yaml")
+				fmt.Fprint(w, "name: doe\ntags:\n- a\nmeta:\n        n: 1\n")
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"doe","tags":["a"]}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, D: os.Stdout, Indent: "  "}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+
+	req = &web.Req{U: svr.URL + "/yaml", D: ``, Indent: "  "}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(req.D)
+
+	// Output:
+	// {
+	//   "name": "doe",
+	//   "tags": [
+	//     "a"
+	//   ]
+	// }
+	// name: doe
+	// tags:
+	//   - a
+	// meta:
+	//   n: 1
+}
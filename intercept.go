@@ -0,0 +1,52 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import "net/http"
+
+// DroppedError is returned (and the proxy responds 502 Bad Gateway)
+// when Intercept or InterceptResponse of the Session dropped the
+// request to URL (or its response) by returning nil.
+type DroppedError struct {
+	URL string
+}
+
+// Error fulfills the error interface.
+func (e DroppedError) Error() string { return "dropped by intercept: " + e.URL }
+
+// intercept returns the transport wrapped by the Intercept and
+// InterceptResponse of the Session (closest to the wire, within any
+// Middleware) or the transport as is without either.
+func (s *Session) intercept(t http.RoundTripper) http.RoundTripper {
+	if s.Intercept == nil && s.InterceptResponse == nil {
+		return t
+	}
+	return RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		u := r.URL.String()
+		if s.Intercept != nil {
+			out, err := s.Intercept(r)
+			if err != nil {
+				return nil, err
+			}
+			if out == nil {
+				return nil, DroppedError{u}
+			}
+			r = out
+		}
+		res, err := t.RoundTrip(r)
+		if err != nil || s.InterceptResponse == nil {
+			return res, err
+		}
+		body := res.Body
+		res, err = s.InterceptResponse(res)
+		if res == nil || err != nil {
+			body.Close()
+			if err != nil {
+				return nil, err
+			}
+			return nil, DroppedError{u}
+		}
+		return res, nil
+	})
+}
@@ -0,0 +1,48 @@
+package web_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleSession_Intercept() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.Header.Get("X-Token"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	s := &web.Session{
+		Intercept: func(r *http.Request) (*http.Request, error) {
+			if r.URL.Path == "/ads" {
+				return nil, nil
+			}
+			r.Header.Set("X-Token", "secret")
+			return r, nil
+		},
+		InterceptResponse: func(res *http.Response) (*http.Response, error) {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			res.Body = io.NopCloser(strings.NewReader(strings.ToUpper(string(body))))
+			return res, nil
+		},
+	}
+
+	req := &web.Req{U: svr.URL, D: "", S: s}
+	fmt.Println(req.Submit(), req.D)
+
+	err := (&web.Req{U: svr.URL + "/ads", S: s}).Submit()
+	fmt.Println(errors.As(err, new(web.DroppedError)))
+
+	// Output:
+	// <nil> SECRET
+	// true
+}
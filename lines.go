@@ -0,0 +1,33 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// MaxLine is the longest line in bytes that a streamed response (see
+// Req) may contain.
+var MaxLine = 1 << 20
+
+// lines calls each with every line read from r until it ends, each
+// returns an error, or ctx is done.
+func lines(ctx context.Context, r io.Reader, each func(string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), MaxLine)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := each(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,51 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_lines() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			for i := 1; ; i++ {
+				fmt.Fprintf(w, "line %v\n", i)
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+					return
+				default:
+				}
+			}
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var count int
+	req := &web.Req{
+		U: svr.URL,
+		C: ctx,
+		D: func(line string) error {
+			if count++; count == 3 {
+				cancel()
+			}
+			fmt.Println(line)
+			return nil
+		},
+	}
+	err := req.Submit()
+	fmt.Println(errors.Is(err, context.Canceled))
+
+	// Output:
+	// line 1
+	// line 2
+	// line 3
+	// true
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import "context"
+
+// metaKey is the type of the context key for request metadata so that
+// it cannot collide with any other.
+type metaKey struct{}
+
+// WithMeta returns a copy of ctx carrying request-scoped metadata (an
+// operation name, correlation ID, etc.) for Middleware and other hooks
+// to read with Meta. Assign the result to the context (C) of a Req.
+// Metadata already in ctx is kept unless overwritten by a key in meta.
+func WithMeta(ctx context.Context, meta map[string]any) context.Context {
+	merged := map[string]any{}
+	for k, v := range Meta(ctx) {
+		merged[k] = v
+	}
+	for k, v := range meta {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, metaKey{}, merged)
+}
+
+// Meta returns the metadata from ctx added with WithMeta or nil if
+// there is none. The map must not be modified.
+func Meta(ctx context.Context) map[string]any {
+	m, _ := ctx.Value(metaKey{}).(map[string]any)
+	return m
+}
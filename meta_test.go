@@ -0,0 +1,37 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleWithMeta() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+
+	tag := func(next web.RoundTripFunc) web.RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			fmt.Println("op:", web.Meta(r.Context())["op"])
+			return next(r)
+		}
+	}
+
+	ctx := web.WithMeta(context.Background(), map[string]any{"op": "ListUsers"})
+	req := &web.Req{
+		U: svr.URL,
+		C: ctx,
+		S: &web.Session{Middleware: []web.Middleware{tag}},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// op: ListUsers
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// RoundTripFunc is a function that fulfills the http.RoundTripper
+// interface.
+type RoundTripFunc func(r *http.Request) (*http.Response, error)
+
+// RoundTrip fulfills the http.RoundTripper interface.
+func (f RoundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Middleware wraps the next RoundTripFunc with cross-cutting behavior
+// (authentication, logging, tracing, etc.) by returning a new one that
+// usually calls next. Middleware is added to the Middleware of
+// a Session with the first being the outermost (called first).
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// chain returns the transport wrapped by all the middleware.
+func chain(t http.RoundTripper, mw []Middleware) http.RoundTripper {
+	if len(mw) == 0 {
+		return t
+	}
+	f := RoundTripFunc(t.RoundTrip)
+	for i := len(mw) - 1; i >= 0; i-- {
+		f = mw[i](f)
+	}
+	return f
+}
+
+// Logging returns Middleware that logs the method, URL, status (or
+// error), and duration of every request with the logger (or the
+// standard logger if nil).
+func Logging(l *log.Logger) Middleware {
+	if l == nil {
+		l = log.Default()
+	}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next(r)
+			dur := time.Since(start).Round(time.Millisecond)
+			if err != nil {
+				l.Printf("%v %v %v (%v)", r.Method, r.URL, err, dur)
+				return res, err
+			}
+			l.Printf("%v %v %v (%v)", r.Method, r.URL, res.StatusCode, dur)
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleMiddleware() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"auth":%q}`, r.Header.Get("Authorization"))
+		}))
+	defer svr.Close()
+
+	auth := func(next web.RoundTripFunc) web.RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("Authorization", "Bearer token")
+			return next(r)
+		}
+	}
+
+	trace := func(next web.RoundTripFunc) web.RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			fmt.Println("before", r.Header.Get("Authorization") != "")
+			res, err := next(r)
+			fmt.Println("after", res.StatusCode)
+			return res, err
+		}
+	}
+
+	s := &web.Session{Middleware: []web.Middleware{auth, trace}}
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, D: data, S: s}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["auth"])
+
+	// Output:
+	// before true
+	// after 200
+	// Bearer token
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Multipart is a multipart/form-data body (B) of Parts in order. The
+// boundary and Content-Type header are added automatically. Since the
+// body is produced as it is sent (with chunked encoding) files larger
+// than memory can be uploaded. Retries send it again only if every Part
+// can be read again (see Part).
+type Multipart []Part
+
+// Part is one field of a Multipart body with the content from (in
+// order of priority) the file at Path (opened only once sent), Body,
+// or Value. A Body that is not an io.Seeker can only be sent once.
+type Part struct {
+	Name        string    // form field name
+	FileName    string    // for a file field (default: base of Path)
+	ContentType string    // default for files: application/octet-stream
+	Path        string    // file to stream content from
+	Body        io.Reader // stream of content
+	Value       string    // plain field value
+}
+
+var quoted = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// header returns the MIME header of the part.
+func (p Part) header() textproto.MIMEHeader {
+	h := textproto.MIMEHeader{}
+	disp := `form-data; name="` + quoted.Replace(p.Name) + `"`
+	name := p.FileName
+	if name == "" && p.Path != "" {
+		name = filepath.Base(p.Path)
+	}
+	ctype := p.ContentType
+	if name != "" {
+		disp += `; filename="` + quoted.Replace(name) + `"`
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+	}
+	h.Set("Content-Disposition", disp)
+	if ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+	return h
+}
+
+// write writes every part to w with the boundary.
+func (m Multipart) write(w io.Writer, boundary string) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+	for _, p := range m {
+		pw, err := mw.CreatePart(p.header())
+		if err != nil {
+			return err
+		}
+		switch {
+		case p.Path != "":
+			f, err := os.Open(p.Path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(pw, f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		case p.Body != nil:
+			if _, err := io.Copy(pw, p.Body); err != nil {
+				return err
+			}
+		default:
+			if _, err := io.WriteString(pw, p.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return mw.Close()
+}
+
+// body returns the Multipart as a body that is only produced once read.
+func (m Multipart) body() *multipartBody {
+	return &multipartBody{parts: m, boundary: multipart.NewWriter(nil).Boundary()}
+}
+
+// multipartBody is written (by another goroutine) into a pipe as it is
+// read so that nothing is buffered.
+type multipartBody struct {
+	parts    Multipart
+	boundary string
+	once     sync.Once
+	r        *io.PipeReader
+}
+
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+func (b *multipartBody) start() {
+	b.once.Do(func() {
+		r, w := io.Pipe()
+		b.r = r
+		go func() { w.CloseWithError(b.parts.write(w, b.boundary)) }()
+	})
+}
+
+// Read fulfills the io.Reader interface.
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.start()
+	return b.r.Read(p)
+}
+
+// Close fulfills the io.Closer interface and stops the writing.
+func (b *multipartBody) Close() error {
+	b.start()
+	return b.r.Close()
+}
+
+// rewind sets the GetBody of the httpreq if every part can be read
+// again, seeking any Body back to where it is now.
+func (b *multipartBody) rewind(httpreq *http.Request) error {
+	pos := make([]int64, len(b.parts))
+	for i, p := range b.parts {
+		if p.Path != "" || p.Body == nil {
+			continue
+		}
+		s, is := p.Body.(io.Seeker)
+		if !is {
+			return nil
+		}
+		n, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		pos[i] = n
+	}
+	httpreq.GetBody = func() (io.ReadCloser, error) {
+		for i, p := range b.parts {
+			if s, is := p.Body.(io.Seeker); is && p.Path == "" {
+				if _, err := s.Seek(pos[i], io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return &multipartBody{parts: b.parts, boundary: b.boundary}, nil
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package web_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleMultipart() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, r.TransferEncoding)
+			mr, err := r.MultipartReader()
+			if err != nil {
+				fmt.Fprintln(w, err)
+				return
+			}
+			for {
+				p, err := mr.NextPart()
+				if err != nil {
+					break
+				}
+				byt, _ := io.ReadAll(p)
+				fmt.Fprintf(w, "%v %q %v %s\n", p.FormName(), p.FileName(),
+					p.Header.Get("Content-Type"), byt)
+			}
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	dir, _ := os.MkdirTemp("", "multipart")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "report.csv")
+	os.WriteFile(path, []byte("a,b"), 0600)
+
+	req := &web.Req{
+		U: svr.URL,
+		M: "POST",
+		D: ``,
+		B: web.Multipart{
+			{Name: "title", Value: "Q1"},
+			{Name: "report", Path: path, ContentType: "text/csv"},
+			{Name: "notes", FileName: "notes.txt", Body: strings.NewReader("hi")},
+		},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(req.D)
+
+	// Output:
+	// [chunked]
+	// title ""  Q1
+	// report "report.csv" text/csv a,b
+	// notes "notes.txt" application/octet-stream hi
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// NDJSON is a newline-delimited JSON body (B) for bulk APIs (such as
+// the _bulk of Elasticsearch) with every value encoded as compact JSON
+// on a line of its own (ending with a newline) in order and sent as
+// application/x-ndjson. Each line is only encoded as it is sent (with
+// chunked encoding) so that the whole body is never buffered. It can
+// always be sent again for a retry.
+type NDJSON []any
+
+// body returns the NDJSON as a body that is encoded as it is read.
+func (n NDJSON) body() *ndjsonBody { return &ndjsonBody{lines: n, left: n} }
+
+// ndjsonBody encodes the next line only once the previous has been
+// read.
+type ndjsonBody struct {
+	lines NDJSON
+	left  NDJSON
+	buf   bytes.Buffer
+}
+
+// Read fulfills the io.Reader interface.
+func (b *ndjsonBody) Read(p []byte) (int, error) {
+	for b.buf.Len() == 0 {
+		if len(b.left) == 0 {
+			return 0, io.EOF
+		}
+		byt, err := json.Marshal(b.left[0])
+		if err != nil {
+			return 0, err
+		}
+		b.left = b.left[1:]
+		b.buf.Write(byt)
+		b.buf.WriteByte('\n')
+	}
+	return b.buf.Read(p)
+}
+
+// rewind sets the GetBody of the httpreq to encode every line again.
+func (b *ndjsonBody) rewind(httpreq *http.Request) {
+	httpreq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(b.lines.body()), nil
+	}
+}
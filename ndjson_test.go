@@ -0,0 +1,40 @@
+package web_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleNDJSON() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			fmt.Printf("%v %v\n%s", r.Header.Get("Content-Type"), r.ContentLength, body)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{
+		M: "POST",
+		U: svr.URL + "/_bulk",
+		B: web.NDJSON{
+			map[string]any{"index": map[string]any{"_id": "1"}},
+			struct {
+				Title string `json:"title"`
+			}{"first"},
+		},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// application/x-ndjson -1
+	// {"index":{"_id":"1"}}
+	// {"title":"first"}
+}
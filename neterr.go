@@ -0,0 +1,72 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// TimeoutError is returned when the request timed out (see TimeOut and
+// Req.C) before a response was received.
+type TimeoutError struct {
+	Err error
+}
+
+// Error fulfills the error interface.
+func (e TimeoutError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the Err.
+func (e TimeoutError) Unwrap() error { return e.Err }
+
+// DNSError is returned when the Host could not be resolved.
+type DNSError struct {
+	Host string
+	Err  error
+}
+
+// Error fulfills the error interface.
+func (e DNSError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the Err.
+func (e DNSError) Unwrap() error { return e.Err }
+
+// ConnRefusedError is returned when nothing is listening at the
+// address (Addr) to connect to.
+type ConnRefusedError struct {
+	Addr string
+	Err  error
+}
+
+// Error fulfills the error interface.
+func (e ConnRefusedError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the Err.
+func (e ConnRefusedError) Unwrap() error { return e.Err }
+
+// netError returns the error from the http.Client wrapped in one of
+// the above (so that it can be detected with errors.As) or as is if it
+// is none of them.
+func netError(err error) error {
+	var dns *net.DNSError
+	if errors.As(err, &dns) {
+		return DNSError{dns.Name, err}
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		var addr string
+		var op *net.OpError
+		if errors.As(err, &op) && op.Addr != nil {
+			addr = op.Addr.String()
+		}
+		return ConnRefusedError{addr, err}
+	}
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &ne) && ne.Timeout()) {
+		return TimeoutError{err}
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	ht "net/http/httptest"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleConnRefusedError() {
+
+	// nothing listening once closed
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	err := (&web.Req{U: "http://" + addr}).Submit()
+	var refused web.ConnRefusedError
+	fmt.Println(errors.As(err, &refused), refused.Addr == addr)
+
+	// Output:
+	// true true
+}
+
+func ExampleTimeoutError() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := (&web.Req{U: svr.URL, C: ctx}).Submit()
+	fmt.Println(errors.As(err, new(web.TimeoutError)))
+	fmt.Println(errors.Is(err, context.DeadlineExceeded))
+
+	// Output:
+	// true
+	// true
+}
+
+func ExampleDNSError() {
+
+	err := (&web.Req{U: "http://nothing.invalid"}).Submit()
+	var dns web.DNSError
+	fmt.Println(errors.As(err, &dns), dns.Host)
+
+	// Output:
+	// true nothing.invalid
+}
@@ -0,0 +1,39 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.23
+
+package web
+
+import "iter"
+
+// Paginate returns an iterator over each page of responses starting
+// with req, each decoded into a new T (see Get). After each page, next
+// is called with it for the Req of the page after it (usually req with
+// another Q) until it returns false. The first error is yielded (with
+// the page as decoded so far) and ends the iteration:
+//
+//     for page, err := range web.Paginate(req, next) {
+//         if err != nil { ... }
+//     }
+//
+// Any D of the requests is ignored.
+func Paginate[T any](req *Req, next func(page T) (*Req, bool)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		req := req // so that every range starts with the first page
+		for req != nil {
+			page, err := submit[T](req)
+			if err != nil {
+				yield(page, err)
+				return
+			}
+			if !yield(page, nil) {
+				return
+			}
+			var more bool
+			if req, more = next(page); !more {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+	"strconv"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExamplePaginate() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			next := n + 1
+			if n >= 3 {
+				next = 0
+			}
+			fmt.Fprintf(w, `{"items":["%va","%vb"],"next":%v}`, n, n, next)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type Page struct {
+		Items []string
+		Next  int
+	}
+	next := func(p Page) (*web.Req, bool) {
+		q := url.Values{"page": {strconv.Itoa(p.Next)}}
+		return &web.Req{U: svr.URL, Q: q}, p.Next > 0
+	}
+
+	first := &web.Req{U: svr.URL, Q: url.Values{"page": {"1"}}}
+	pages := web.Paginate(first, next)
+	for page, err := range pages {
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Println(page.Items)
+	}
+
+	// ranging again starts over with the first page
+	for page := range pages {
+		fmt.Println(page.Items)
+		break
+	}
+
+	// Output:
+	// [1a 1b]
+	// [2a 2b]
+	// [3a 3b]
+	// [1a 1b]
+}
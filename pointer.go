@@ -0,0 +1,111 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PointerError is returned by DecodeAt when nothing in the response is
+// at the JSON Pointer.
+type PointerError struct {
+	Pointer string
+}
+
+// Error fulfills the error interface.
+func (e PointerError) Error() string {
+	return fmt.Sprintf("nothing at JSON pointer: %q", e.Pointer)
+}
+
+// DecodeAt unmarshals only the part of the JSON response (buffered by
+// Submit) at the RFC 6901 JSON Pointer (ptr) into out. Everything before
+// it is skipped without being decoded and nothing after it is read at
+// all. An empty ptr is the whole response. Leave D nil so that Submit
+// does not decode the whole response as well.
+func (req *Req) DecodeAt(ptr string, out any) error {
+	if req.body == nil {
+		return errors.New("no response body to decode")
+	}
+	if ptr != "" && !strings.HasPrefix(ptr, "/") {
+		return ReqSyntaxError{"invalid JSON pointer: " + ptr}
+	}
+	dec := json.NewDecoder(bytes.NewReader(req.body))
+	if ptr != "" {
+		for _, ref := range strings.Split(ptr[1:], "/") {
+			ref = strings.ReplaceAll(strings.ReplaceAll(ref, "~1", "/"), "~0", "~")
+			if err := seek(dec, ref); err != nil {
+				if errors.Is(err, errNotFound) {
+					return PointerError{ptr}
+				}
+				return err
+			}
+		}
+	}
+	return dec.Decode(out)
+}
+
+var errNotFound = errors.New("not found")
+
+// seek advances the decoder to the value of the member or element
+// (ref) of the object or array that is next.
+func seek(dec *json.Decoder, ref string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if key == ref {
+				return nil
+			}
+			if err := skip(dec); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		n, err := strconv.Atoi(ref)
+		if err != nil || n < 0 || (len(ref) > 1 && ref[0] == '0') {
+			return errNotFound
+		}
+		for i := 0; dec.More(); i++ {
+			if i == n {
+				return nil
+			}
+			if err := skip(dec); err != nil {
+				return err
+			}
+		}
+	}
+	return errNotFound
+}
+
+// skip reads past the next value of the decoder without decoding it.
+func skip(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_DecodeAt() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"meta":{"n":2},"data":{"items":[
+				{"name":"first","tags":["a"]},
+				{"name":"second","tags":["b","c"]}
+			],"a/b":true}}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+
+	var item struct {
+		Name string
+		Tags []string
+	}
+	fmt.Println(req.DecodeAt(`/data/items/1`, &item), item)
+
+	var slash bool
+	fmt.Println(req.DecodeAt(`/data/a~1b`, &slash), slash)
+
+	fmt.Println(req.DecodeAt(`/data/items/2`, &item))
+
+	// Output:
+	// <nil> {second [b c]}
+	// <nil> true
+	// nothing at JSON pointer: "/data/items/2"
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// hopHeaders are removed by the proxy since they only apply to
+// a single connection.
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Proxy starts an HTTP forward proxy listening on addr that sends every
+// request through the transport of the Session (nil for defaults) so
+// that its Middleware can log or rewrite requests and responses and its
+// HAR (see RecordHAR) records the traffic. HTTPS (CONNECT) requests are
+// tunneled to the destination as is since they cannot be inspected
+// without terminating TLS. Proxy blocks until the server fails.
+func Proxy(addr string, s *Session) error {
+	return http.ListenAndServe(addr, ProxyHandler(s))
+}
+
+// ProxyHandler returns the http.Handler used by Proxy.
+func ProxyHandler(s *Session) http.Handler {
+	if s == nil {
+		s = new(Session)
+	}
+	return &forwarder{s: s, rt: s.roundTripper(s.transport())}
+}
+
+type forwarder struct {
+	s  *Session
+	rt http.RoundTripper
+}
+
+// ServeHTTP fulfills the http.Handler interface.
+func (p *forwarder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "proxy requires absolute URL", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	for _, k := range hopHeaders {
+		out.Header.Del(k)
+	}
+
+	start := time.Now()
+	res, err := p.rt.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+	resbody, err := io.ReadAll(res.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if p.s.har != nil {
+		p.s.har.add(start, out, string(body), res, resbody)
+	}
+
+	for _, k := range hopHeaders {
+		res.Header.Del(k)
+	}
+	for k, vals := range res.Header {
+		for _, v := range vals {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	w.Write(resbody)
+}
+
+// tunnel connects the client directly to the CONNECT destination.
+func (p *forwarder) tunnel(w http.ResponseWriter, r *http.Request) {
+	dest, err := net.DialTimeout("tcp", p.s.resolve(r.Host), 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hj, is := w.(http.Hijacker)
+	if !is {
+		dest.Close()
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	conn, bufrw, err := hj.Hijack()
+	if err != nil {
+		dest.Close()
+		return
+	}
+	if n := bufrw.Reader.Buffered(); n > 0 { // already read from client
+		byt, _ := bufrw.Reader.Peek(n)
+		if _, err := dest.Write(byt); err != nil {
+			dest.Close()
+			conn.Close()
+			return
+		}
+	}
+	go func() {
+		io.Copy(dest, conn)
+		dest.Close()
+	}()
+	io.Copy(conn, dest)
+	conn.Close()
+}
@@ -0,0 +1,46 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleProxyHandler() {
+
+	backend := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"via":%q}`, r.Header.Get("X-Via"))
+		}))
+	defer backend.Close()
+
+	rewrite := func(next web.RoundTripFunc) web.RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("X-Via", "web")
+			return next(r)
+		}
+	}
+	px := ht.NewServer(web.ProxyHandler(
+		&web.Session{Middleware: []web.Middleware{rewrite}},
+	))
+	defer px.Close()
+
+	pxurl, _ := url.Parse(px.URL)
+	defer func(c *http.Client) { web.Client = c }(web.Client)
+	web.Client = &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(pxurl)},
+	}
+
+	data := map[string]any{}
+	req := &web.Req{U: backend.URL, D: data}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["via"])
+
+	// Output:
+	// web
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// target returns the URL with the query string of RawQuery, Params, and
+// Q added (if any) along with the Query of the Session for any keys
+// that are not already in any of them (or U).
+func (req *Req) target() (string, error) {
+	q, err := req.query()
+	if err != nil {
+		return "", err
+	}
+	if req.S != nil && len(req.S.Query) > 0 {
+		vals := url.Values{}
+		MergeValues(vals, req.S.Query)
+		_, inU, _ := strings.Cut(req.U, "?")
+		for _, raw := range []string{inU, req.RawQuery} {
+			given, _ := url.ParseQuery(raw)
+			for k := range given {
+				delete(vals, k)
+			}
+		}
+		MergeValues(vals, q)
+		q = vals
+	}
+	query := req.RawQuery
+	if len(q) > 0 {
+		if query != "" {
+			query += "&"
+		}
+		query += q.Encode()
+	}
+	if query == "" {
+		return req.U, nil
+	}
+	if strings.Contains(req.U, "?") {
+		return req.U + "&" + query, nil
+	}
+	return req.U + "?" + query, nil
+}
+
+// MergeValues sets every key of src in dst replacing (rather than
+// appending to) all the values dst had for it so that a key with
+// several values in src has only those values in dst. Keys only in dst
+// are kept as is. The values are copied.
+func MergeValues(dst, src url.Values) {
+	for k, v := range src {
+		dst[k] = append([]string(nil), v...)
+	}
+}
+
+// query returns the combined query string values of Params and Q.
+func (req *Req) query() (url.Values, error) {
+	if req.Params == nil {
+		return req.Q, nil
+	}
+	vals, err := Values(req.Params)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.Q {
+		vals[k] = append(vals[k], v...)
+	}
+	return vals, nil
+}
+
+// Values returns the url.Values from the exported fields of a struct
+// (or pointer to one) in the manner of github.com/google/go-querystring.
+// The name of each field is used as is unless a url tag gives another
+// name. The tag may also have an omitempty option to leave out zero
+// values and a name of "-" skips the field entirely:
+//
+//     type Params struct {
+//         Search string   `url:"q"`
+//         Tags   []string `url:"tag,omitempty"` // tag=a&tag=b
+//         Page   int      `url:"page,omitempty"`
+//         Secret string   `url:"-"`
+//     }
+//
+// Slices and arrays are repeated parameters, time.Time is RFC 3339,
+// anything that is an encoding.TextMarshaler or fmt.Stringer is
+// converted as such, and embedded structs have their fields included
+// as if those of the outer struct. A url.Values is returned as is.
+func Values(v any) (url.Values, error) {
+	if vals, is := v.(url.Values); is {
+		return vals, nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, ReqSyntaxError{fmt.Sprintf("unsupported Params type: %T", v)}
+	}
+	vals := url.Values{}
+	addFields(vals, rv)
+	return vals, nil
+}
+
+// addFields adds the values of the fields of the struct (rv) to vals.
+func addFields(vals url.Values, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("url"), ",")
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		omit := opts == "omitempty"
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				addFields(vals, fv)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if omit && fv.IsZero() {
+			continue
+		}
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		switch fv.Kind() {
+		case reflect.Pointer:
+			vals.Add(name, "") // nil
+		case reflect.Slice, reflect.Array:
+			if fv.Type().Elem().Kind() == reflect.Uint8 {
+				vals.Add(name, str(fv))
+				continue
+			}
+			for n := 0; n < fv.Len(); n++ {
+				vals.Add(name, str(fv.Index(n)))
+			}
+		default:
+			vals.Add(name, str(fv))
+		}
+	}
+}
+
+// str returns the query string value of a single field value.
+func str(v reflect.Value) string {
+	switch i := v.Interface().(type) {
+	case time.Time:
+		return i.Format(time.RFC3339)
+	case []byte:
+		return string(i)
+	case encoding.TextMarshaler:
+		if byt, err := i.MarshalText(); err == nil {
+			return string(byt)
+		}
+	case fmt.Stringer:
+		return i.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
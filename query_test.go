@@ -0,0 +1,182 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleValues() {
+
+	type Paging struct {
+		Page int `url:"page,omitempty"`
+	}
+
+	type Params struct {
+		Paging
+		Search string    `url:"q"`
+		Tags   []string  `url:"tag,omitempty"`
+		Since  time.Time `url:"since,omitempty"`
+		Secret string    `url:"-"`
+		Limit  *int      `url:"limit,omitempty"`
+		Raw    bool
+	}
+
+	vals, err := web.Values(Params{
+		Paging: Paging{Page: 2},
+		Search: "go web",
+		Tags:   []string{"a", "b"},
+		Secret: "shh",
+	})
+	fmt.Println(vals.Encode(), err)
+
+	_, err = web.Values("q=go")
+	fmt.Println(err)
+
+	// Output:
+	// Raw=false&page=2&q=go+web&tag=a&tag=b <nil>
+	// unsupported Params type: string
+}
+
+func ExampleReq_Submit_params() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.URL.RawQuery)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{
+		U: svr.URL,
+		D: ``,
+		Params: struct {
+			ID   int    `url:"id"`
+			Sort string `url:"sort,omitempty"`
+		}{ID: 42},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// Output:
+	// id=42
+}
+
+func ExampleReq_Submit_rawQuery() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.RequestURI)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	// no trailing question mark when there is nothing to add
+	req := &web.Req{U: svr.URL + "/path", D: ``, Q: url.Values{}}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// never re-encoded or reordered
+	req = &web.Req{
+		U: svr.URL + "/path", D: ``,
+		RawQuery: "z=1&a=%7e&sig=a+b",
+		Q:        url.Values{"page": {"2"}},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// Output:
+	// /path
+	// /path?z=1&a=%7e&sig=a+b&page=2
+}
+
+func ExampleReq_Submit_emptyQuery() {
+
+	var uris []string
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			uris = append(uris, r.RequestURI)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type Params struct {
+		Page int    `url:"page,omitempty"`
+		Sort string `url:"sort,omitempty"`
+	}
+
+	reqs := []*web.Req{
+		{U: svr.URL},
+		{U: svr.URL, Q: url.Values{}},
+		{U: svr.URL, Params: Params{}},
+		{U: svr.URL, Params: Params{}, Q: url.Values{}},
+	}
+	for _, req := range reqs {
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+	}
+	fmt.Println(uris)
+
+	// the same request when cached as well
+	var count int
+	s := new(web.Session)
+	svr.Config.Handler = http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { count++ })
+	s.Cached(time.Minute, &web.Req{U: svr.URL, Q: url.Values{}})
+	s.Cached(time.Minute, &web.Req{U: svr.URL})
+	fmt.Println(count)
+
+	// Output:
+	// [/ / / /]
+	// 1
+}
+
+func ExampleMergeValues() {
+
+	dst := url.Values{"a": {"1", "2"}, "b": {"1"}}
+	web.MergeValues(dst, url.Values{"a": {"3"}, "c": {"1", "2"}})
+	fmt.Println(dst.Encode())
+
+	// Output:
+	// a=3&b=1&c=1&c=2
+}
+
+func ExampleSession_Query() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.URL.RawQuery)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	s := &web.Session{Query: url.Values{"api_version": {"2"}, "lang": {"en"}}}
+
+	reqs := []*web.Req{
+		{U: svr.URL, S: s, D: ``},
+		{U: svr.URL, S: s, D: ``, Q: url.Values{"lang": {"fr", "de"}, "q": {"go"}}},
+		{U: svr.URL + "?api_version=3", S: s, D: ``},
+	}
+	for _, req := range reqs {
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(req.D)
+	}
+
+	// Output:
+	// api_version=2&lang=en
+	// api_version=2&lang=fr&lang=de&q=go
+	// api_version=3&lang=en
+}
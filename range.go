@@ -0,0 +1,53 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentRange is the part of the whole that the body of a 206 Partial
+// Content response is (from its Content-Range header).
+type ContentRange struct {
+	Start int64 // first byte position
+	End   int64 // last byte position (inclusive)
+	Size  int64 // of the whole or -1 if unknown
+}
+
+// partial returns the ContentRange of a 206 response or nil if it is
+// not one or has no single range.
+func partial(res *http.Response) *ContentRange {
+	if res.StatusCode != http.StatusPartialContent {
+		return nil
+	}
+	val := strings.TrimSpace(res.Header.Get("Content-Range"))
+	unit, spec, found := strings.Cut(val, " ")
+	if !found || !strings.EqualFold(unit, "bytes") {
+		return nil
+	}
+	span, size, found := strings.Cut(spec, "/")
+	if !found {
+		return nil
+	}
+	first, last, found := strings.Cut(span, "-")
+	if !found {
+		return nil
+	}
+	cr := &ContentRange{Size: -1}
+	var err error
+	if cr.Start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return nil
+	}
+	if cr.End, err = strconv.ParseInt(last, 10, 64); err != nil {
+		return nil
+	}
+	if size != "*" {
+		if cr.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return nil
+		}
+	}
+	return cr
+}
@@ -0,0 +1,45 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_range() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			content := strings.NewReader("0123456789")
+			http.ServeContent(w, r, "digits.txt", time.Time{}, content)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, D: ``, Range: "bytes=2-5"}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.R.StatusCode, req.D, *req.Partial)
+
+	req = &web.Req{U: svr.URL, D: ``, Range: "bytes=-3"}
+	req.Submit()
+	fmt.Println(req.R.StatusCode, req.D, *req.Partial)
+
+	req = &web.Req{U: svr.URL, D: ``}
+	req.Submit()
+	fmt.Println(req.R.StatusCode, req.D, req.Partial)
+
+	req = &web.Req{U: svr.URL, D: ``, Range: "bytes=20-"}
+	fmt.Println(req.Submit())
+
+	// Output:
+	// 206 2345 {2 5 10}
+	// 206 789 {7 9 10}
+	// 200 0123456789 <nil>
+	// 416 Requested Range Not Satisfiable
+}
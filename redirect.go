@@ -0,0 +1,137 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RedirectPolicy determines how a Session handles a redirect to
+// a different host than that of the original request.
+type RedirectPolicy int
+
+const (
+	FollowCrossHost RedirectPolicy = iota // net/http default behavior
+	StripCrossHost                        // drop SensitiveHeaders first
+	BlockCrossHost                        // fail with CrossHostError
+)
+
+// SensitiveHeaders are removed from any request that is redirected to
+// a different host when the StripCrossHost policy is in effect.
+var SensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+}
+
+// CrossHostError is returned when a redirect to a different host is
+// blocked by the BlockCrossHost policy.
+type CrossHostError struct {
+	From string
+	To   string
+}
+
+// Error fulfills the error interface.
+func (e CrossHostError) Error() string {
+	return fmt.Sprintf("blocked redirect from %v to %v", e.From, e.To)
+}
+
+// RedirectLoopError is returned when a redirect leads back to a URL
+// that was already requested instead of stopping after 10 redirects.
+// The Cycle is every URL from the first time it was requested until the
+// redirect back to it.
+type RedirectLoopError struct {
+	Cycle []string
+}
+
+// Error fulfills the error interface.
+func (e RedirectLoopError) Error() string {
+	return "redirect loop: " + strings.Join(e.Cycle, " -> ")
+}
+
+// loops returns a copy of the client that fails with a RedirectLoopError
+// before applying the CheckRedirect of the client (if any).
+func loops(client *http.Client) *http.Client {
+	c := *client
+	check := client.CheckRedirect
+	c.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		to := r.URL.String()
+		for i, v := range via {
+			if v.URL.String() != to {
+				continue
+			}
+			var cycle []string
+			for _, v := range via[i:] {
+				cycle = append(cycle, v.URL.String())
+			}
+			return RedirectLoopError{append(cycle, to)}
+		}
+		if check != nil {
+			return check(r, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &c
+}
+
+// checkRedirect is assigned to the CheckRedirect of the Session client
+// and otherwise behaves like the net/http default (stopping after 10
+// redirects).
+func (s *Session) checkRedirect(r *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	from := via[0].URL
+	if r.URL.Hostname() == from.Hostname() {
+		return nil
+	}
+	switch s.CrossHost {
+	case StripCrossHost:
+		for _, k := range SensitiveHeaders {
+			r.Header.Del(k)
+		}
+	case BlockCrossHost:
+		return CrossHostError{from.String(), r.URL.String()}
+	}
+	return nil
+}
+
+// Hop is a single response in the RedirectChain of a Req. The Body is
+// only kept (up to DrainLimit bytes) for the redirect responses.
+type Hop struct {
+	URL    string
+	Status int
+	Body   []byte
+}
+
+// trace returns a copy of the client that adds every redirect response
+// to the RedirectChain before applying the CheckRedirect of the client
+// (if any).
+func (req *Req) trace(client *http.Client) *http.Client {
+	traced := *client
+	check := client.CheckRedirect
+	traced.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		if res := r.Response; res != nil {
+			body, _ := io.ReadAll(io.LimitReader(res.Body, DrainLimit))
+			req.RedirectChain = append(req.RedirectChain,
+				Hop{res.Request.URL.String(), res.StatusCode, body})
+		}
+		if check != nil {
+			return check(r, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &traced
+}
@@ -0,0 +1,97 @@
+package web_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleRedirectPolicy() {
+
+	other := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"auth":%q}`, r.Header.Get("Authorization"))
+		}))
+	defer other.Close()
+
+	// 127.0.0.1 and localhost are different hosts
+	target := "http://localhost:" + other.URL[len("http://127.0.0.1:"):]
+	svr := ht.NewServer(http.RedirectHandler(target, http.StatusFound))
+	defer svr.Close()
+
+	data := map[string]any{}
+	req := &web.Req{
+		U: svr.URL,
+		D: data,
+		H: web.Head{"Authorization": "Bearer secret"},
+		S: &web.Session{CrossHost: web.StripCrossHost},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%q\n", data["auth"])
+
+	req = &web.Req{
+		U: svr.URL,
+		S: &web.Session{CrossHost: web.BlockCrossHost},
+	}
+	err := req.Submit()
+	fmt.Println(errors.As(err, &web.CrossHostError{}))
+
+	// Output:
+	// ""
+	// true
+}
+
+func ExampleReq_TraceRedirects() {
+
+	mux := http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusMovedPermanently))
+	mux.Handle("/b", http.RedirectHandler("/c", http.StatusFound))
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "done")
+	})
+	svr := ht.NewServer(mux)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL + "/a", D: ``, TraceRedirects: true}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	for _, hop := range req.RedirectChain {
+		fmt.Println(hop.Status, hop.URL[len(svr.URL):], len(hop.Body) > 0)
+	}
+
+	// Output:
+	// 301 /a true
+	// 302 /b true
+	// 200 /c false
+}
+
+func ExampleRedirectLoopError() {
+
+	next := map[string]string{"/a": "/b", "/b": "/c", "/c": "/b"}
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, next[r.URL.Path], http.StatusFound)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	err := (&web.Req{U: svr.URL + "/a"}).Submit()
+	var loop web.RedirectLoopError
+	fmt.Println(errors.As(err, &loop))
+	for _, u := range loop.Cycle {
+		fmt.Println(strings.TrimPrefix(u, svr.URL))
+	}
+
+	// Output:
+	// true
+	// /b
+	// /c
+	// /b
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// requestID adds the RequestIDHeader of the Session to r unless it has
+// one already with the "request-id" of its Meta or else a new UUID.
+func (s *Session) requestID(r *http.Request) error {
+	name := s.requestIDHeader()
+	if r.Header.Get(name) != "" {
+		return nil
+	}
+	if id, has := Meta(r.Context())["request-id"]; has {
+		r.Header.Set(name, fmt.Sprint(id))
+		return nil
+	}
+	id, err := uuid()
+	if err != nil {
+		return err
+	}
+	r.Header.Set(name, id)
+	return nil
+}
+
+// requestIDHeader returns the RequestIDHeader or the default.
+func (s *Session) requestIDHeader() string {
+	if s.RequestIDHeader == "" {
+		return "X-Request-ID"
+	}
+	return s.RequestIDHeader
+}
+
+// uuid returns a new random (version 4) UUID.
+func uuid() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
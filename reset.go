@@ -0,0 +1,56 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"io"
+	"reflect"
+)
+
+// Reset clears everything set by Submit (R, Trailer, SetCookies,
+// Partial, RedirectChain, Timings, RawRequest, RawResponse, RequestID,
+// and the buffered response) and empties D (and any OnStatus data) so
+// that the same Req can be submitted again, in a polling loop for
+// example. Submit itself never changes M, U, or H. A string D becomes
+// empty, a map has all keys deleted, and the value of any other
+// pointer (except an io.Writer, which is kept as is) is set to its
+// zero value.
+func (req *Req) Reset() {
+	req.R = nil
+	req.Trailer = nil
+	req.SetCookies = nil
+	req.Partial = nil
+	req.RedirectChain = nil
+	req.Timings = Timings{}
+	req.RawRequest = nil
+	req.RawResponse = nil
+	req.RequestID = ""
+	req.body = nil
+	req.D = empty(req.D)
+	for code, data := range req.OnStatus {
+		req.OnStatus[code] = empty(data)
+	}
+}
+
+// empty returns the data emptied for reuse (see Reset).
+func empty(data any) any {
+	switch data.(type) {
+	case nil, io.Writer:
+		return data
+	case string:
+		return ""
+	}
+	rv := reflect.ValueOf(data)
+	switch rv.Kind() {
+	case reflect.Map:
+		for _, k := range rv.MapKeys() {
+			rv.SetMapIndex(k, reflect.Value{})
+		}
+	case reflect.Pointer:
+		if !rv.IsNil() {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+		}
+	}
+	return data
+}
@@ -0,0 +1,40 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Reset() {
+
+	var count int
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			count++
+			if count == 1 {
+				fmt.Fprintf(w, `{"a":%v,"q":%q}`, count, r.URL.RawQuery)
+				return
+			}
+			fmt.Fprintf(w, `{"b":%v,"q":%q}`, count, r.URL.RawQuery)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+	req := &web.Req{M: "get", U: svr.URL, Q: url.Values{"x": {"1"}}, D: data}
+	for i := 0; i < 2; i++ {
+		req.Reset()
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(data, req.M, req.U == svr.URL)
+	}
+
+	// Output:
+	// map[a:1 q:x=1] get true
+	// map[b:2 q:x=1] get true
+}
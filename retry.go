@@ -0,0 +1,220 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// MaxRetryAfter is the most time in seconds that Submit will spend
+// waiting in total before retrying (see Req.RetryOn), whether to honor
+// the Retry-After header of a response (such as 429 Too Many Requests)
+// or backing off. No more than 10 retries are ever attempted. Set to 0
+// to disable.
+var MaxRetryAfter int = 60
+
+// MaxRetries is the most times that Submit retries a request that
+// failed without a Retry-After header saying when to, waiting 100ms
+// before the first and twice as long before each after that.
+var MaxRetries int = 3
+
+// Retryable is the default when a Req has no RetryOn. It is true for
+// a 429 Too Many Requests, any 5xx response other than 501 Not
+// Implemented and 505 HTTP Version Not Supported, and connections that
+// were refused or reset. Since only a 429 or refused connection means
+// the server did not process the request, Submit only retries the
+// others for idempotent methods (not POST or PATCH).
+func Retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED) ||
+			errors.Is(err, syscall.ECONNRESET)
+	}
+	switch code := res.StatusCode; {
+	case code == http.StatusTooManyRequests:
+		return true
+	case code == http.StatusNotImplemented,
+		code == http.StatusHTTPVersionNotSupported:
+		return false
+	default:
+		return code >= 500
+	}
+}
+
+// retry returns true if the response (or error) of the httpreq is one
+// to retry according to retryOn (or Retryable when nil).
+func retry(
+	httpreq *http.Request, res *http.Response, err error,
+	retryOn func(*http.Response, error) bool,
+) bool {
+	if httpreq.Context().Err() != nil {
+		return false
+	}
+	if retryOn != nil {
+		return retryOn(res, err)
+	}
+	if !Retryable(res, err) {
+		return false
+	}
+	if res != nil && res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	switch httpreq.Method {
+	case "POST", "PATCH", "CONNECT":
+		return false
+	}
+	return true
+}
+
+// do sends the httpreq with the client retrying (see Req.RetryOn) after
+// the Retry-After of the response (or with backoff up to MaxRetries
+// times without one) as long as the waiting fits within MaxRetryAfter
+// and the budget (nil for none) allows.
+func do(
+	client *http.Client, httpreq *http.Request,
+	retryOn func(*http.Response, error) bool, budget *RetryBudget,
+) (*http.Response, error) {
+	left := time.Duration(MaxRetryAfter) * time.Second
+	backoff := 100 * time.Millisecond
+	budget.sent()
+	for tries := 0; ; tries++ {
+		res, err := client.Do(httpreq)
+		if !retry(httpreq, res, err, retryOn) {
+			return res, err
+		}
+		var wait time.Duration
+		var ok bool
+		if res != nil {
+			wait, ok = retryAfter(res.Header.Get("Retry-After"))
+		}
+		if !ok {
+			if tries >= MaxRetries {
+				return res, err
+			}
+			wait, backoff = backoff, backoff*2
+		}
+		if wait > left || tries >= 10 {
+			return res, err
+		}
+		if httpreq.Body != nil && httpreq.Body != http.NoBody && httpreq.GetBody == nil {
+			if res == nil {
+				return res, err
+			}
+			drain(res)
+			return res, RewindError{res.Status}
+		}
+		if !budget.spend() {
+			return res, err
+		}
+		left -= wait
+		if res != nil {
+			drain(res)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-httpreq.Context().Done():
+			timer.Stop()
+			return nil, httpreq.Context().Err()
+		case <-timer.C:
+		}
+		if httpreq.GetBody != nil {
+			body, err := httpreq.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			httpreq.Body = body
+		}
+	}
+}
+
+// RewindError is returned instead of retrying (see MaxRetryAfter) when
+// the request body is an io.Reader that has already been consumed and
+// cannot be sent again because it is not an io.Seeker. Use a string,
+// []byte, *bytes.Reader, or *os.File for bodies that must be retried.
+type RewindError struct {
+	Status string // of the response that would have been retried
+}
+
+// Error fulfills the error interface.
+func (e RewindError) Error() string {
+	return "cannot retry after " + e.Status + ": request body is not rewindable"
+}
+
+// rewind sets the GetBody of httpreq (unless net/http already has for
+// the likes of *bytes.Buffer) so that a streamed body can be sent again
+// for a retry. Only an io.Seeker can be rewound, which is sought back to
+// where it began each time. It is also no longer closed by net/http
+// since that would prevent reading it again (so that a *os.File remains
+// for the caller to close).
+func rewind(httpreq *http.Request, body io.Reader) error {
+	if m, is := body.(*multipartBody); is {
+		return m.rewind(httpreq)
+	}
+	if n, is := body.(*ndjsonBody); is {
+		n.rewind(httpreq)
+		return nil
+	}
+	if httpreq.GetBody != nil {
+		return nil
+	}
+	s, is := body.(io.Seeker)
+	if !is {
+		return nil
+	}
+	pos, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	httpreq.ContentLength = end - pos
+	httpreq.Body = io.NopCloser(body)
+	httpreq.GetBody = func() (io.ReadCloser, error) {
+		if _, err := s.Seek(pos, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(body), nil
+	}
+	if httpreq.ContentLength == 0 {
+		httpreq.Body = http.NoBody
+	}
+	return nil
+}
+
+// retryAfter parses the value of a Retry-After header which is either
+// a number of seconds or an HTTP date.
+func retryAfter(val string) (time.Duration, bool) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(val); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	t, err := http.ParseTime(val)
+	if err != nil {
+		return 0, false
+	}
+	wait := time.Until(t)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
@@ -0,0 +1,150 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleMaxRetryAfter() {
+
+	var count int
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			count++
+			if count < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprintf(w, `{"tries":%v}`, count)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, D: data}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["tries"])
+
+	// Output:
+	// 3
+}
+
+func ExampleReq_Submit_maxTotalTime() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, MaxTotalTime: 100 * time.Millisecond}
+	err := req.Submit()
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, context.DeadlineExceeded))
+
+	// Output:
+	// exceeded MaxTotalTime of 100ms: context deadline exceeded
+	// true
+}
+
+func ExampleRewindError() {
+
+	var count int
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			count++
+			if count%2 == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			byt, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s", byt)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	// seekable so sent again after the 429
+	var out string
+	body := struct{ io.ReadSeeker }{strings.NewReader("some data")}
+	req := &web.Req{U: svr.URL, M: "POST", B: body, D: &out}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(out)
+
+	// can only be read once
+	stream := struct{ io.Reader }{strings.NewReader("some data")}
+	req = &web.Req{U: svr.URL, M: "POST", B: stream}
+	err := req.Submit()
+	fmt.Println(err)
+	fmt.Println(errors.As(err, new(web.RewindError)))
+
+	// Output:
+	// some data
+	// cannot retry after 429 Too Many Requests: request body is not rewindable
+	// true
+}
+
+func ExampleReq_Submit_retryOn() {
+
+	var count int
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			count++
+			if count < 3 {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"error":"locked"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"tries":%v}`, count)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	locked := func(res *http.Response, err error) bool {
+		if err != nil || res.StatusCode != http.StatusConflict {
+			return false
+		}
+		byt, _ := io.ReadAll(res.Body)
+		res.Body = io.NopCloser(bytes.NewReader(byt))
+		return strings.Contains(string(byt), "locked")
+	}
+
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, M: "POST", D: data, RetryOn: locked}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["tries"])
+
+	// a 500 is retried by default but never a POST
+	count = -10
+	svr.Config.Handler = http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			count++
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+	fmt.Println((&web.Req{U: svr.URL, M: "POST"}).Submit(), count)
+	count = 0
+	fmt.Println((&web.Req{U: svr.URL}).Submit(), count)
+
+	// Output:
+	// 3
+	// 500 Internal Server Error -9
+	// 500 Internal Server Error 4
+}
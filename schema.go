@@ -0,0 +1,43 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaError is returned by Submit when the response body does not
+// validate against the JSON Schema of the Req. The wrapped Err (usually
+// a *jsonschema.ValidationError) describes every mismatch.
+type SchemaError struct {
+	Err error
+}
+
+// Error fulfills the error interface.
+func (e SchemaError) Error() string { return "schema: " + e.Err.Error() }
+
+// Unwrap returns the underlying validation error.
+func (e SchemaError) Unwrap() error { return e.Err }
+
+// validate returns a SchemaError unless the JSON byt is valid according
+// to the JSON Schema.
+func validate(schema, byt []byte) error {
+	sch, err := jsonschema.CompileString("schema.json", string(schema))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(byt))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return SchemaError{err}
+	}
+	if err := sch.Validate(v); err != nil {
+		return SchemaError{err}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package web_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_schema() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"id":%v}`, r.URL.Query().Get("id"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	schema := []byte(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {"id": {"type": "integer"}}
+	}`)
+
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL + "?id=1", D: data, Schema: schema}
+	fmt.Println(req.Submit(), data["id"])
+
+	req = &web.Req{U: svr.URL + `?id="one"`, D: map[string]any{}, Schema: schema}
+	err := req.Submit()
+	fmt.Println(errors.As(err, &web.SchemaError{}))
+
+	// Output:
+	// <nil> 1
+	// true
+}
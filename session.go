@@ -0,0 +1,298 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Session holds settings shared by every Req assigned to it (S) that
+// cannot be set on an individual request because they belong to the
+// underlying transport. The http.Client for the Session is created the
+// first time it is needed and reused after that so that connections
+// can be kept alive between requests. Changing the fields of a Session
+// after its first use has no effect.
+type Session struct {
+
+	// Resolve maps a host (or host:port) to the IP address to dial
+	// instead of the one from DNS (like curl --resolve). The Host
+	// header and TLS server name remain those of the URL.
+	Resolve map[string]string
+
+	// ServerName is sent as the TLS SNI and used to verify the server
+	// certificate instead of the host from the URL. Combine with
+	// Resolve and Req.Host for virtual host and canary testing.
+	ServerName string
+
+	// CrossHost is the policy for redirects that lead to a different
+	// host than the original request, which could otherwise leak
+	// credentials to a third party (see SensitiveHeaders).
+	CrossHost RedirectPolicy
+
+	// AcceptEncoding is added as the Accept-Encoding header of every
+	// request that does not have one already. Since setting the header
+	// disables the automatic gzip decoding of net/http it must only
+	// contain encodings that are Decodable (or Submit will fail).
+	AcceptEncoding string
+
+	// LocalAddr is the local IP address that outgoing connections
+	// are bound to (originate from) on a multi-homed host.
+	LocalAddr string
+
+	// DisableKeepAlives opens a new connection for every request
+	// (useful for load testing) instead of reusing them.
+	DisableKeepAlives bool
+
+	// NextProtos are the TLS ALPN protocols offered to the server
+	// exactly as given (in order of preference) instead of h2 and
+	// http/1.1 (the default). HTTP/2 is only used if h2 is one of them.
+	// The one negotiated is the R.TLS.NegotiatedProtocol of a Req.
+	NextProtos []string
+
+	// UploadRate is the most bytes per second of any request body to
+	// send (to simulate a slow client). Zero is no limit.
+	UploadRate int
+
+	// ForceHTTP10 sends every request as HTTP/1.0 (request line) for
+	// testing odd or old servers. Connections are never reused and
+	// HTTP/2 is never negotiated. A streamed body (see Req) is read into
+	// memory first to be sent with a Content-Length (not chunked).
+	ForceHTTP10 bool
+
+	// Query holds default query string values added to the URL of every
+	// request for any key that it does not have already (so that those
+	// of the Req replace them, see MergeValues).
+	Query url.Values
+
+	// Cache keeps the responses of Cached (default: a MemoryCache).
+	Cache Cache
+
+	// StaleWhileRevalidate is how long after it expires a response kept
+	// by Cached is still used while it is revalidated in the background.
+	StaleWhileRevalidate time.Duration
+
+	// RetryBudget (if any) limits how many requests are retried in all
+	// (see Req.RetryOn) and is shared by the Session.
+	RetryBudget *RetryBudget
+
+	// Middleware wraps the transport of the Session with the first
+	// being the outermost (see Middleware).
+	Middleware []Middleware
+
+	// Intercept is called with every request (including redirects and
+	// retries, and those of the Proxy) just before it is sent so that it
+	// can be inspected or rewritten in flight. Returning the same request
+	// (changed or not) sends it, returning another sends that one
+	// instead, returning nil drops it (see DroppedError), and returning
+	// an error fails it without sending anything.
+	Intercept func(r *http.Request) (*http.Request, error)
+
+	// InterceptResponse is called with every response as soon as it is
+	// received (before its body is read) and works the same way as
+	// Intercept. The body of a response that is dropped or fails is
+	// closed but that of one replaced by another is not.
+	InterceptResponse func(res *http.Response) (*http.Response, error)
+
+	// RequestID adds a correlation ID header (RequestIDHeader) to every
+	// request without one so that it can be traced across services. The
+	// ID is the "request-id" of the Meta of the context of the request
+	// if there is one or else a new random UUID (see Req.RequestID).
+	RequestID       bool
+	RequestIDHeader string // default: X-Request-ID
+
+	// UserAgents are used in turn (see Rotation) as the User-Agent
+	// header of every request that does not have one already.
+	UserAgents []string
+
+	// Rotation determines which of the UserAgents is used next.
+	Rotation Rotation
+
+	// Seed is the seed of the RandomRotation so that the order of
+	// UserAgents can be reproduced (in tests). Zero uses a different
+	// order every time.
+	Seed int64
+
+	har    *harRecorder // see RecordHAR
+	client *http.Client
+	once   sync.Once
+
+	mu      sync.Mutex   // guards all that follow
+	memory  *MemoryCache // without Cache
+	flights map[string]*flight
+	agent   int
+	rand    *rand.Rand
+	certs   map[*tls.Certificate]*http.Client
+}
+
+// Rotation determines how a Session chooses from its UserAgents.
+type Rotation int
+
+const (
+	RoundRobin     Rotation = iota // each in order, then start over
+	RandomRotation                 // any one at random every time
+)
+
+// Client returns the http.Client built from the Session fields,
+// creating it on first call.
+func (s *Session) Client() *http.Client {
+	s.once.Do(func() {
+		s.client = &http.Client{
+			Transport:     s.roundTripper(s.transport()),
+			CheckRedirect: s.checkRedirect,
+		}
+	})
+	return s.client
+}
+
+// Warm opens a connection (DNS, TCP, and TLS) to the host of the URL
+// before it is needed with a HEAD request so that the first real
+// request reuses it rather than waiting for it (unless
+// DisableKeepAlives). The response status does not matter.
+func (s *Session) Warm(u string) error {
+	dur := time.Duration(time.Second * time.Duration(TimeOut))
+	ctx, cancel := context.WithTimeout(context.Background(), dur)
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, "HEAD", u, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.prepare(r); err != nil {
+		return err
+	}
+	res, err := s.Client().Do(r)
+	if err != nil {
+		return netError(err)
+	}
+	io.Copy(io.Discard, res.Body)
+	return res.Body.Close()
+}
+
+// roundTripper returns the transport wrapped by everything the Session
+// adds to it (see Middleware and Intercept).
+func (s *Session) roundTripper(t *http.Transport) http.RoundTripper {
+	var rt http.RoundTripper = t
+	if s.ForceHTTP10 {
+		rt = tlsState(t)
+	}
+	return chain(s.intercept(rt), s.Middleware)
+}
+
+// prepare applies the Session settings that belong to each request
+// rather than the transport.
+func (s *Session) prepare(r *http.Request) error {
+	if s.LocalAddr != "" && net.ParseIP(s.LocalAddr) == nil {
+		return ReqSyntaxError{"invalid LocalAddr: " + s.LocalAddr}
+	}
+	if s.AcceptEncoding != "" && r.Header.Get("Accept-Encoding") == "" {
+		if !decodable(s.AcceptEncoding) {
+			return ReqSyntaxError{
+				"undecodable Accept-Encoding: " + s.AcceptEncoding,
+			}
+		}
+		r.Header.Set("Accept-Encoding", s.AcceptEncoding)
+	}
+	if len(s.UserAgents) > 0 && r.Header.Get("User-Agent") == "" {
+		r.Header.Set("User-Agent", s.userAgent())
+	}
+	if s.RequestID {
+		if err := s.requestID(r); err != nil {
+			return err
+		}
+	}
+	if s.ForceHTTP10 {
+		if err := buffer(r); err != nil {
+			return err
+		}
+	}
+	throttle(r, s.UploadRate)
+	return nil
+}
+
+// userAgent returns the next of the UserAgents (see Rotation).
+func (s *Session) userAgent() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Rotation == RandomRotation {
+		if s.rand == nil {
+			seed := s.Seed
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+			s.rand = rand.New(rand.NewSource(seed))
+		}
+		return s.UserAgents[s.rand.Intn(len(s.UserAgents))]
+	}
+	ua := s.UserAgents[s.agent%len(s.UserAgents)]
+	s.agent++
+	return ua
+}
+
+func (s *Session) transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if ip := net.ParseIP(s.LocalAddr); ip != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+	t.DialContext = func(c context.Context, nw, addr string) (net.Conn, error) {
+		return dialer.DialContext(c, nw, s.resolve(addr))
+	}
+	t.DisableKeepAlives = s.DisableKeepAlives
+	if s.ServerName != "" {
+		t.TLSClientConfig = &tls.Config{ServerName: s.ServerName}
+	}
+	if len(s.NextProtos) > 0 {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.NextProtos = s.NextProtos
+		if !listed(s.NextProtos, "h2") {
+			t.ForceAttemptHTTP2 = false
+			t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	}
+	if s.ForceHTTP10 {
+		http10(t)
+	}
+	return t
+}
+
+// resolve returns addr with the host replaced by the Resolve entry
+// for host:port or host (in that order) if there is one.
+func (s *Session) resolve(addr string) string {
+	if s.Resolve == nil {
+		return addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip, has := s.Resolve[addr]; has {
+		return net.JoinHostPort(ip, port)
+	}
+	if ip, has := s.Resolve[host]; has {
+		return net.JoinHostPort(ip, port)
+	}
+	return addr
+}
+
+// listed returns true if the list has the string.
+func listed(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
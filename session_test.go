@@ -0,0 +1,239 @@
+package web_test
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleSession_resolve() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"host":%q}`, r.Host)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+	u, _ := url.Parse(svr.URL)
+
+	s := &web.Session{Resolve: map[string]string{"example.test": "127.0.0.1"}}
+	data := map[string]any{}
+
+	req := &web.Req{U: "http://example.test:" + u.Port(), D: data, S: s}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["host"] == "example.test:"+u.Port())
+
+	// Output:
+	// true
+}
+
+func ExampleSession_localAddr() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			fmt.Fprintf(w, `{"from":%q}`, host)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, D: data, S: &web.Session{LocalAddr: "127.0.0.1"}}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["from"])
+
+	req = &web.Req{U: svr.URL, S: &web.Session{LocalAddr: "nowhere"}}
+	fmt.Println(req.Submit())
+
+	// Output:
+	// 127.0.0.1
+	// invalid LocalAddr: nowhere
+}
+
+func ExampleSession_userAgents() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.UserAgent())
+		}))
+	defer svr.Close()
+
+	s := &web.Session{UserAgents: []string{"one", "two"}}
+	for i := 0; i < 3; i++ {
+		req := &web.Req{U: svr.URL, D: ``, S: s}
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(req.D)
+	}
+
+	req := &web.Req{U: svr.URL, D: ``, S: s, H: web.Head{"User-Agent": "mine"}}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// Output:
+	// one
+	// two
+	// one
+	// mine
+}
+
+func ExampleSession_forceHTTP10() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.Proto)
+			if r.URL.Path == "/len" {
+				body, _ := io.ReadAll(r.Body)
+				fmt.Fprintf(w, " %v %v %s", r.ContentLength, r.TransferEncoding, body)
+			}
+		}))
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, D: ``, S: &web.Session{ForceHTTP10: true}}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// streamed bodies are sent with a Content-Length (never chunked)
+	req = &web.Req{
+		M: `POST`,
+		U: svr.URL + `/len`,
+		B: io.MultiReader(strings.NewReader(`some `), strings.NewReader(`body`)),
+		D: ``,
+		S: &web.Session{ForceHTTP10: true},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// Output:
+	// HTTP/1.0
+	// HTTP/1.0 9 [] some body
+}
+
+func ExampleSession_uploadRate() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			byt, _ := io.ReadAll(r.Body)
+			fmt.Fprint(w, len(byt))
+		}))
+	defer svr.Close()
+
+	start := time.Now()
+	req := &web.Req{
+		M: `POST`,
+		U: svr.URL,
+		B: strings.Repeat("x", 500),
+		D: ``,
+		S: &web.Session{UploadRate: 2000},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D, time.Since(start) >= 250*time.Millisecond)
+
+	// Output:
+	// 500 true
+}
+
+func ExampleSession_Warm() {
+
+	var conns int
+	svr := ht.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+	svr.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			conns++
+		}
+	}
+	svr.Start()
+	defer svr.Close()
+
+	s := new(web.Session)
+	if err := s.Warm(svr.URL); err != nil {
+		fmt.Println(err)
+	}
+
+	req := &web.Req{U: svr.URL, S: s, TraceTimings: true}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.Timings.Reused, conns)
+
+	// Output:
+	// true 1
+}
+
+func ExampleSession_NextProtos() {
+
+	var offered []string
+	svr := ht.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+	svr.TLS = &tls.Config{
+		GetConfigForClient: func(h *tls.ClientHelloInfo) (*tls.Config, error) {
+			offered = h.SupportedProtos
+			return nil, nil
+		},
+	}
+	svr.Config.ErrorLog = log.New(io.Discard, "", 0)
+	svr.StartTLS()
+	defer svr.Close()
+
+	// the certificate is not trusted but the protocols are offered first
+	s := &web.Session{NextProtos: []string{"acme-tls/1", "http/1.1"}}
+	(&web.Req{U: svr.URL, S: s}).Submit()
+	fmt.Println(offered)
+
+	// Output:
+	// [acme-tls/1 http/1.1]
+}
+
+func ExampleSession_RequestID() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, r.Header.Get("X-Correlation-ID"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	s := &web.Session{RequestID: true, RequestIDHeader: "X-Correlation-ID"}
+
+	ctx := web.WithMeta(context.Background(), map[string]any{"request-id": "abc-123"})
+	req := &web.Req{U: svr.URL, C: ctx, S: s, D: ``}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D, req.RequestID)
+
+	// a new UUID (version 4) otherwise
+	req = &web.Req{U: svr.URL, S: s, D: ``}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D == req.RequestID, len(req.RequestID), req.RequestID[14:15])
+
+	// Output:
+	// abc-123 abc-123
+	// true 36 4
+}
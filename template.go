@@ -0,0 +1,29 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"strings"
+	"text/template"
+)
+
+// render returns the BodyTemplate executed against Vars. A reference
+// to a variable missing from Vars is an error rather than silently
+// sending "<no value>".
+func (req *Req) render() (string, error) {
+	tmpl, err := template.New("body").Option("missingkey=error").
+		Parse(req.BodyTemplate)
+	if err != nil {
+		return "", ReqSyntaxError{"invalid body template: " + err.Error()}
+	}
+	vars := req.Vars
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,40 @@
+package web_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Submit_bodyTemplate() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			byt, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s", byt)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{
+		M:            `POST`,
+		U:            svr.URL,
+		D:            ``,
+		BodyTemplate: `{"user":"{{.user}}","id":{{.id}}}`,
+		Vars:         map[string]string{"user": "rwxrob", "id": "42"},
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	req = &web.Req{M: `POST`, U: svr.URL, BodyTemplate: `{{.missing}}`}
+	fmt.Println(req.Submit() != nil)
+
+	// Output:
+	// {"user":"rwxrob","id":42}
+	// true
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TestHandler submits the Req to the handler directly (with a response
+// kept in memory) instead of over the network so that handlers can be
+// tested with the same Req (and D) used against a real server but much
+// faster than with an httptest.Server. The host of U does not matter
+// but U must still be a valid URL (http://test/path). Everything about
+// the response (R, D, OnStatus, errors) is the same as Submit. Any
+// Session (S) of the Req is ignored.
+func TestHandler(h http.Handler, req *Req) error {
+	serve := func(_ RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			sr := r.Clone(r.Context())
+			sr.RequestURI = r.URL.RequestURI()
+			sr.RemoteAddr = "192.0.2.1:1234"
+			rec := &recorder{header: http.Header{}}
+			h.ServeHTTP(rec, sr)
+			res := rec.response()
+			res.Request = r
+			return res, nil
+		}
+	}
+	s := req.S
+	defer func() { req.S = s }()
+	req.S = &Session{Middleware: []Middleware{serve}}
+	return req.Submit()
+}
+
+// recorder is the http.ResponseWriter of a TestHandler which keeps the
+// response in memory. As with a real server, the headers are those at
+// the time of the first Write (or WriteHeader) and a missing
+// Content-Type is detected from the first bytes written.
+type recorder struct {
+	header http.Header
+	sent   http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// Header fulfills the http.ResponseWriter interface.
+func (rec *recorder) Header() http.Header { return rec.header }
+
+// WriteHeader fulfills the http.ResponseWriter interface.
+func (rec *recorder) WriteHeader(code int) {
+	if rec.sent != nil {
+		return
+	}
+	rec.code, rec.sent = code, rec.header.Clone()
+}
+
+// Write fulfills the http.ResponseWriter interface.
+func (rec *recorder) Write(p []byte) (int, error) {
+	if rec.sent == nil {
+		if rec.header.Get("Content-Type") == "" && len(p) > 0 {
+			rec.header.Set("Content-Type", http.DetectContentType(p))
+		}
+		rec.WriteHeader(http.StatusOK)
+	}
+	return rec.body.Write(p)
+}
+
+// Flush fulfills the http.Flusher interface (for streaming handlers).
+func (rec *recorder) Flush() {
+	if rec.sent == nil {
+		rec.WriteHeader(http.StatusOK)
+	}
+}
+
+// response returns the response written so far.
+func (rec *recorder) response() *http.Response {
+	rec.Flush()
+	return &http.Response{
+		Status:        fmt.Sprintf("%03d %v", rec.code, http.StatusText(rec.code)),
+		StatusCode:    rec.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.sent,
+		Body:          io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+		ContentLength: int64(rec.body.Len()),
+	}
+}
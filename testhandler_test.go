@@ -0,0 +1,49 @@
+package web_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleTestHandler() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/page" {
+				fmt.Fprint(w, "<html><p>hi</p></html>")
+				w.Header().Set("X-Late", "ignored once written")
+				return
+			}
+			if r.URL.Path != "/users" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"method": r.Method,
+				"name":   r.URL.Query().Get("name"),
+			})
+		})
+
+	type User struct {
+		Method string `json:"method"`
+		Name   string `json:"name"`
+	}
+	user := new(User)
+	req := &web.Req{U: "http://test/users?name=doe", M: "PUT", D: user}
+	fmt.Println(web.TestHandler(handler, req), *user, req.R.StatusCode)
+
+	req = &web.Req{U: "http://test/nowhere"}
+	fmt.Println(web.TestHandler(handler, req))
+
+	req = &web.Req{U: "http://test/page", D: ``}
+	fmt.Println(web.TestHandler(handler, req), req.R.Header, req.D)
+
+	// Output:
+	// <nil> {PUT doe} 200
+	// 404 Not Found
+	// <nil> map[Content-Type:[text/html; charset=utf-8]] <html><p>hi</p></html>
+}
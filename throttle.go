@@ -0,0 +1,68 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// throttle replaces the body of the request (and its GetBody) with one
+// that is read no faster than rate bytes per second (see
+// Session.UploadRate).
+func throttle(r *http.Request, rate int) {
+	if r.Body == nil || r.Body == http.NoBody || rate <= 0 {
+		return
+	}
+	ctx := r.Context()
+	r.Body = &throttled{ReadCloser: r.Body, ctx: ctx, rate: rate}
+	if get := r.GetBody; get != nil {
+		r.GetBody = func() (io.ReadCloser, error) {
+			body, err := get()
+			if err != nil {
+				return nil, err
+			}
+			return &throttled{ReadCloser: body, ctx: ctx, rate: rate}, nil
+		}
+	}
+}
+
+// throttled reads from ReadCloser in small chunks sleeping as long as
+// needed between them to stay within the rate (bytes per second).
+type throttled struct {
+	io.ReadCloser
+	ctx   context.Context
+	rate  int
+	start time.Time
+	read  int64
+}
+
+// Read fulfills the io.Reader interface.
+func (t *throttled) Read(p []byte) (int, error) {
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+	chunk := t.rate / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	if len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := t.ReadCloser.Read(p)
+	t.read += int64(n)
+	due := time.Duration(float64(t.read) / float64(t.rate) * float64(time.Second))
+	if wait := due - time.Since(t.start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-t.ctx.Done():
+			return n, t.ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings are how long each phase of a Req took (see TraceTimings).
+// DNS, Connect, and TLS are zero when a kept-alive connection is
+// Reused (or, for TLS, when not https). TTFB (time to first byte) and
+// Total (including reading the whole body) are since the request was
+// sent. With redirects or retries the phases are those of the last
+// connection.
+type Timings struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+	Total   time.Duration
+	Reused  bool
+}
+
+// timer records the Timings of a request with httptrace.
+type timer struct {
+	mu      sync.Mutex
+	start   time.Time
+	dns     time.Time
+	connect time.Time
+	tls     time.Time
+	t       *Timings
+}
+
+// traced returns the httpreq with a trace context that records into t.
+func traced(httpreq *http.Request, t *Timings) (*http.Request, *timer) {
+	tm := &timer{start: time.Now(), t: t}
+	*t = Timings{}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { tm.mark(&tm.dns) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tm.since(&t.DNS, &tm.dns)
+		},
+		ConnectStart: func(string, string) { tm.mark(&tm.connect) },
+		ConnectDone: func(string, string, error) {
+			tm.since(&t.Connect, &tm.connect)
+		},
+		TLSHandshakeStart: func() { tm.mark(&tm.tls) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tm.since(&t.TLS, &tm.tls)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			tm.mu.Lock()
+			t.Reused = info.Reused
+			tm.mu.Unlock()
+		},
+		GotFirstResponseByte: func() { tm.since(&t.TTFB, &tm.start) },
+	}
+	ctx := httptrace.WithClientTrace(httpreq.Context(), trace)
+	return httpreq.WithContext(ctx), tm
+}
+
+// mark sets the time of the start of a phase.
+func (tm *timer) mark(at *time.Time) {
+	tm.mu.Lock()
+	*at = time.Now()
+	tm.mu.Unlock()
+}
+
+// since sets the duration d to the time since the start of a phase.
+func (tm *timer) since(d *time.Duration, start *time.Time) {
+	tm.mu.Lock()
+	*d = time.Since(*start)
+	tm.mu.Unlock()
+}
+
+// done sets the Total.
+func (tm *timer) done() {
+	tm.mu.Lock()
+	tm.t.Total = time.Since(tm.start)
+	tm.mu.Unlock()
+}
@@ -0,0 +1,35 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"time"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_TraceTimings() {
+
+	svr := ht.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(10 * time.Millisecond)
+			fmt.Fprint(w, "done")
+		}))
+	defer svr.Close()
+
+	s := &web.Session{}
+	for i := 0; i < 2; i++ {
+		req := &web.Req{U: svr.URL, D: ``, S: s, TraceTimings: true}
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+		t := req.Timings
+		fmt.Println(t.Reused, t.Connect > 0, t.TTFB >= 10*time.Millisecond,
+			t.Total >= t.TTFB)
+	}
+
+	// Output:
+	// false true true true
+	// true false true true
+}
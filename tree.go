@@ -0,0 +1,48 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Tree returns the response (buffered by Submit) decoded as a generic
+// tree of map[string]any, []any, and values no matter what D is, for
+// exploring a response without first declaring a type for it. The
+// Content-Type of the response determines the decoding the same as for
+// D (see Req) with anything else decoded as JSON (or YAML).
+func (req *Req) Tree() (any, error) {
+	if req.body == nil || req.R == nil {
+		return nil, errors.New("no response body to decode")
+	}
+	var tree any
+	tmp := &Req{D: &tree, StrictJSON: req.StrictJSON}
+	if err := tmp.decode(req.R.Header.Get("Content-Type"), req.body); err != nil {
+		return nil, err
+	}
+	return normal(tree), nil
+}
+
+// normal replaces every map with keys of any type (from CBOR and the
+// like) in the tree with a map[string]any.
+func normal(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normal(val)
+		}
+		return m
+	case map[string]any:
+		for k, val := range v {
+			v[k] = normal(val)
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = normal(val)
+		}
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+
+	"github.com/fxamacker/cbor/v2"
+	web "github.com/rwxrob/web"
+)
+
+func ExampleReq_Tree() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/cbor" {
+				byt, _ := cbor.Marshal(map[string]any{"n": 1, "tags": []string{"a"}})
+				w.Header().Set("Content-Type", "application/cbor")
+				w.Write(byt)
+				return
+			}
+			fmt.Fprint(w, `{"name":"doe","items":[{"id":1},{"id":2}]}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	tree, err := req.Tree()
+	if err != nil {
+		fmt.Println(err)
+	}
+	m := tree.(map[string]any)
+	fmt.Println(m["name"], m["items"].([]any)[1].(map[string]any)["id"])
+
+	req = &web.Req{U: svr.URL + "/cbor"}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	tree, _ = req.Tree()
+	fmt.Printf("%T %v\n", tree, tree)
+
+	// Output:
+	// doe 2
+	// map[string]interface {} map[n:1 tags:[a]]
+}
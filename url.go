@@ -0,0 +1,30 @@
+// Copyright 2022 web Robert Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"net/url"
+	"strings"
+)
+
+// JoinPath returns the base URL with each of the segments percent
+// encoded (including any slash) and appended to its path. Any query
+// string or fragment of base is preserved. Use JoinPath rather than
+// interpolating raw values into URL paths.
+func JoinPath(base string, segments ...string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	p := strings.TrimSuffix(u.EscapedPath(), "/")
+	for _, s := range segments {
+		p += "/" + url.PathEscape(s)
+	}
+	u.Path, err = url.PathUnescape(p)
+	if err != nil {
+		return "", err
+	}
+	u.RawPath = p
+	return u.String(), nil
+}
@@ -0,0 +1,20 @@
+package web_test
+
+import (
+	"fmt"
+
+	web "github.com/rwxrob/web"
+)
+
+func ExampleJoinPath() {
+	u, err := web.JoinPath(`https://example.com/api/`, `users`, `a/b c`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(u)
+	u, _ = web.JoinPath(`https://example.com/v1?x=1`, `100%`)
+	fmt.Println(u)
+	// Output:
+	// https://example.com/api/users/a%2Fb%20c
+	// https://example.com/v1/100%25?x=1
+}
@@ -7,19 +7,27 @@
 package web
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/fxamacker/cbor/v2"
 	rwxjson "github.com/rwxrob/json"
+	"github.com/vmihailenco/msgpack/v5"
 	"gopkg.in/yaml.v3"
 )
 
@@ -38,6 +46,20 @@ type HTTPError struct {
 // Error fulfills the error interface.
 func (e HTTPError) Error() string { return e.Resp.Status }
 
+// PreconditionFailedError is returned instead of an HTTPError for a 412
+// Precondition Failed response, most often because the resource changed
+// since its ETag was sent as IfMatch (so that it can be read, modified,
+// and written again). It unwraps to the HTTPError.
+type PreconditionFailedError struct {
+	Resp *http.Response
+}
+
+// Error fulfills the error interface.
+func (e PreconditionFailedError) Error() string { return e.Resp.Status }
+
+// Unwrap returns the HTTPError of the response.
+func (e PreconditionFailedError) Unwrap() error { return HTTPError{e.Resp} }
+
 // ReqSyntaxError is for any error involving the incorrect
 // definition of Req fields (such as including a question mark in
 // the URL, etc.).
@@ -48,6 +70,28 @@ type ReqSyntaxError struct {
 // Error fulfills the error interface.
 func (e ReqSyntaxError) Error() string { return e.Message }
 
+// TotalTimeError is returned when a Req takes longer than its
+// MaxTotalTime. The error that interrupted the request (usually
+// wrapping context.DeadlineExceeded) is kept as Err.
+type TotalTimeError struct {
+	Limit time.Duration
+	Err   error
+}
+
+// Error fulfills the error interface.
+func (e TotalTimeError) Error() string {
+	return fmt.Sprintf("exceeded MaxTotalTime of %v: %v", e.Limit, e.Err)
+}
+
+// Unwrap returns the Err.
+func (e TotalTimeError) Unwrap() error { return e.Err }
+
+// DrainLimit is the maximum number of bytes read from the body of an
+// error response so that the connection can be returned to the pool
+// and kept alive. The bytes read remain available from the Resp.Body
+// of the HTTPError. Responses with larger bodies are simply closed.
+var DrainLimit int64 = 64 << 10
+
 // Client provides a way to change the default HTTP client for any
 // further package HTTP request function calls. The Client can also be
 // set in any Req by assigning the to the field of the same name. By
@@ -60,6 +104,26 @@ var Client = http.DefaultClient
 // precision the net/http library directly should be used instead.
 type Head map[string]string
 
+// has returns true if the header map contains the key in any case.
+func has(h Head, key string) bool {
+	for k := range h {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the value of the header key in any case (see has).
+func value(h Head, key string) string {
+	for k, v := range h {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
 // Req is a human-friendly way to think of web requests. This design
 // is closer a pragmatic curl requests than the canonical specification
 // (unique headers, for example). The type and parameters of the web
@@ -71,27 +135,71 @@ type Head map[string]string
 // submitted as data portion of the request:
 //
 //     url.Values - triggers x-www-form-urlencoded
+//     GraphQL    - GraphQL JSON request envelope
 //     byte       - uuencoded binary data
 //     string     - plain text
+//     io.Reader  - streamed as is (read fully only to Compress)
+//     Multipart  - multipart/form-data streamed part by part
+//     NDJSON     - newline-delimited JSON streamed line by line
+//     [][]string - text/csv records (see Comma)
+//     []T        - text/csv with a header record for structs
+//
+// An io.Reader that is also an io.Seeker (*os.File, *bytes.Reader) is
+// sought back to where it began for every retry (see MaxRetryAfter) and
+// is never closed. Any other io.Reader can only be sent once so a
+// response that would be retried is a RewindError instead.
+//
+// A Content-Type header (H) that names a supported encoding takes
+// priority over the type of B:
+//
+//     application/msgpack - MessagePack encoded
+//     application/cbor    - CBOR encoded
+//     application/toml    - TOML encoded
+//     text/csv            - CSV encoded (see Comma)
+//
+// A BodyTemplate is rendered with text/template against Vars (so that
+// {{.name}} is replaced with Vars["name"]) and sent as is instead of B.
 //
-// Note that Req has no support for multi-part MIME. Use net/http
-// directly if such is required.
+// A Multipart body is sent as multipart/form-data streaming each Part
+// (such as a file) as it is sent.
 //
 // The data (D) field can also be any of several types that trigger how
 // the received data is handled:
 //
-//     []byte           - uudecoded binary
-//     string           - plain text string
-//     io.Writer        - keep as is
-//     json.This        - unmarshaled JSON data into This
-//     any              - unmarshaled JSON data
+//     []byte             - uudecoded binary
+//     string             - plain text string (converted to UTF-8)
+//     io.Writer          - written to as it arrives (never buffered)
+//     func(string) error - called with each line as it arrives
+//     json.This          - unmarshaled JSON data into This
+//     *GraphQLResult     - GraphQL JSON response envelope
+//     *[]T               - top-level array (json tags apply for JSON)
+//     any                - unmarshaled JSON data
+//
+// A func(string) error is called with every line of the response
+// (without the line ending) as soon as it is received, which suits
+// endless streams (tailing a log, for example) that would never finish
+// being buffered. Streaming stops at the first error returned (which
+// Submit then returns) or when the context (C) is done. Since the
+// default TimeOut applies to the whole stream, assign C to stream for
+// longer.
+//
+// A response Content-Type of application/msgpack, application/cbor, or
+// application/toml is decoded as MessagePack, CBOR, or TOML into
+// D instead.
+//
+// A text/csv response is decoded into a *[][]string (every record) or
+// a *[]T of structs (see Comma) but to any other D as usual.
 //
 // Passing the query string as url.Values automatically add
 // a question mark (?) followed by the URL encoded values to the end of
 // the URL which may present a problem if the URL already has a query
 // string. Encouraging the use of url.Values for passing the query
 // string serves as a reminder that all query strings should be URL
-// encoded (as is often forgotten).
+// encoded (as is often forgotten). A struct of typed parameters may
+// be assigned to Params instead (see Values) and is combined with Q.
+// Nothing is added when there are no values at all. A RawQuery is sent
+// exactly as is before any others (for signed URLs that must not be
+// re-encoded or reordered).
 type Req struct {
 	U string          // base url, optional query string
 	D any             // data to be populated and/or overwritten
@@ -101,6 +209,120 @@ type Req struct {
 	B any             // body data, url.Values will x-www-form-urlencoded
 	C context.Context // trigger requests with context
 	R *http.Response  // actual http.Response
+	S *Session        // shared transport settings (default: Client)
+
+	Params   any    // struct encoded as query string like Q (see Values)
+	RawQuery string // query string sent exactly as is (never re-encoded)
+
+	// MethodOverride sends a PUT, PATCH, or DELETE as a POST with an
+	// X-HTTP-Method-Override header of the method instead (for gateways
+	// that only allow GET and POST). Other methods are sent as is.
+	MethodOverride bool
+
+	// Range is sent as the Range header (bytes=0-1023, bytes=-512) to
+	// request only part of the response. A server that honors it
+	// responds with 206 Partial Content (a success like any 200) and
+	// Partial is set to which part of the whole the body is. A server
+	// that does not responds with the whole (200) and Partial is nil.
+	Range   string
+	Partial *ContentRange
+
+	// IfMatch is sent as the If-Match header (usually the ETag from
+	// when the resource was read) so that a PUT or PATCH is only done if
+	// the resource has not changed since (see PreconditionFailedError).
+	IfMatch string
+
+	// ClientCert is presented for TLS client authentication (mTLS) by
+	// this request only (for acting as several identities at once).
+	// Since a different certificate needs a different transport (and
+	// connections), one is made the first time a *tls.Certificate is
+	// used (with the Session or Client) and then reused for every Req
+	// with the same pointer so that its connections are kept alive.
+	// Making a new *tls.Certificate for every Req opens a new connection
+	// (and keeps another transport) every time.
+	ClientCert *tls.Certificate
+
+	Host       string         // Host header to send instead of the one from U or H
+	RequestID  string         // correlation ID sent (see Session.RequestID)
+	Trailer    http.Header    // response trailers, set once body has been read
+	SetCookies []*http.Cookie // cookies set by the response (Set-Cookie)
+
+	Compress string // gzip or zstd to compress the body (Content-Encoding)
+	Schema   []byte // JSON Schema that response must validate against
+
+	// MaxBodySize is used instead of the package MaxBodySize when not
+	// zero (negative for no limit at all).
+	MaxBodySize int64
+
+	// Comma separates the fields of a text/csv response decoded into
+	// a *[][]string or *[]T of structs, or a body (B) of either encoded
+	// as one (default: ','). The first record for structs is the header
+	// naming the column of each field by its csv tag (or field name).
+	// Such a body is only encoded as CSV when H has no Content-Type (a
+	// JSON or YAML one encodes it as that instead).
+	Comma rune
+
+	// StrictJSON decodes the response into D with encoding/json
+	// instead of YAML (which accepts anything) so that any field that
+	// is not in D (a struct) is an error and numbers are json.Number.
+	StrictJSON bool
+
+	// OnStatus maps response status codes to the data to populate
+	// instead of D (decoded the same way). Responses that are not in
+	// the 200s still return an HTTPError but have also been decoded
+	// into their OnStatus data (up to DrainLimit bytes). A string is
+	// replaced in the map itself.
+	OnStatus map[int]any
+
+	// IsError is called with every successful (200s) response and its
+	// body before it is decoded. Any error returned is returned from
+	// Submit instead (for APIs that report errors with a 200). Neither
+	// IsError nor Schema can be used with a D that is streamed (an
+	// io.Writer or func(string) error) since the body is never buffered.
+	IsError func(res *http.Response, body []byte) error
+
+	TraceRedirects bool  // keep every response URL and status in RedirectChain
+	RedirectChain  []Hop // redirects followed then the final response
+
+	TraceTimings bool    // measure how long each phase took into Timings
+	Timings      Timings // set by Submit when TraceTimings
+
+	// Dump keeps the request and final response exactly as they are
+	// written on the wire (request line or status line, headers, and
+	// body) in RawRequest and RawResponse (see httputil.DumpRequestOut
+	// and httputil.DumpResponse). Both bodies are buffered to do so.
+	Dump        bool
+	RawRequest  []byte
+	RawResponse []byte
+
+	// RetryOn returns true if the response (or error without one) is to
+	// be retried (see MaxRetryAfter and MaxRetries). Nil uses Retryable
+	// (but never retries a non-idempotent method unless the server did
+	// not process it). A body is retried only if it can be sent again
+	// (see RewindError). The body of res may be read to decide as long
+	// as it is replaced with one having the same content.
+	RetryOn func(res *http.Response, err error) bool
+
+	// MaxTotalTime limits the whole Submit including every retry,
+	// redirect, and reading the body (see TotalTimeError) regardless of
+	// any other timeout.
+	MaxTotalTime time.Duration
+
+	// Indent re-encodes a JSON or YAML response written to a string or
+	// io.Writer D with every level indented by it (such as two spaces)
+	// instead of as it was received (which means it is buffered). YAML
+	// is indented by the number of characters of Indent.
+	Indent string
+
+	BodyTemplate string            // text/template body, used instead of B
+	Vars         map[string]string // values for {{.name}} in BodyTemplate
+
+	// RawBody is sent exactly as is instead of B without any encoding,
+	// compression, Content-Type, or Content-Length (so chunked) for
+	// testing servers with edge cases.
+	RawBody []byte
+
+	body []byte // buffered response body (decompressed)
 }
 
 // Submit synchronously sends the Req to server and populates the
@@ -108,112 +330,659 @@ type Req struct {
 // 200s will result in an HTTPError. See Req for details on how
 // inspection of Req will change the behavior of Submit
 // automatically. It Req.C is nil a context.WithTimeout will
-// be used and with the value of web.TimeOut.
+// be used and with the value of web.TimeOut. Response bodies with
+// a Content-Encoding of gzip, deflate, br, or zstd are decompressed
+// transparently before being decoded. A Req with no body (B) sends
+// neither a body nor a Content-Length.
 func (req *Req) Submit() error {
+	httpreq, buf, err := req.build()
+	if err != nil {
+		return err
+	}
+	return req.send(httpreq, buf, false)
+}
+
+// Do is the same as Submit but returns as soon as the response (R) has
+// arrived without reading or decoding its body (D, OnStatus, IsError,
+// and Schema are ignored) and without an HTTPError for a status that is
+// not in the 200s so that the caller can handle it. The body has been
+// decompressed (see Submit) and must always be closed by the caller,
+// which also ends the context.WithTimeout from web.TimeOut (or of
+// MaxTotalTime) to which reading it is still subject. Responses from Do
+// are never recorded in a HAR.
+func (req *Req) Do() (*http.Response, error) {
+	httpreq, buf, err := req.build()
+	if err != nil {
+		return nil, err
+	}
+	if err := req.send(httpreq, buf, true); err != nil {
+		return nil, err
+	}
+	return req.R, nil
+}
+
+// build returns the http.Request to send for the Req and the text of
+// its body (for the HAR) unless streamed.
+func (req *Req) build() (*http.Request, string, error) {
 
-	if req.M == "" {
-		req.M = `GET`
+	if err := req.Validate(); err != nil {
+		return nil, "", err
 	}
-	req.M = strings.ToUpper(req.M)
 
-	if strings.Index(req.U, "?") == 0 && req.Q != nil {
-		req.U = req.U + "?" + req.Q.Encode()
+	// the method is never normalized in M itself
+	method := strings.ToUpper(req.M)
+	if method == "" {
+		method = `GET`
+	}
+
+	u, err := req.target()
+	if err != nil {
+		return nil, "", err
 	}
 
 	var bodyReader io.Reader
 
-	if req.H == nil {
-		req.H = Head{}
+	// headers added by Submit are never kept in H itself
+	head := Head{}
+	for k, v := range req.H {
+		head[k] = v
+	}
+
+	// an io.Reader is streamed as is unless it must be compressed
+	stream, streaming := req.B.(io.Reader)
+	if parts, is := req.B.(Multipart); is {
+		body := parts.body()
+		head["Content-Type"] = body.contentType()
+		stream, streaming = body, true
+	}
+	if lines, is := req.B.(NDJSON); is {
+		head["Content-Type"] = "application/x-ndjson"
+		stream, streaming = lines.body(), true
+	}
+	streaming = streaming && req.Compress == ""
+
+	// RawBody is sent as is but without a known length (chunked)
+	raw := req.RawBody != nil
+	if raw {
+		stream, streaming = struct{ io.Reader }{bytes.NewReader(req.RawBody)}, true
+	}
+
+	var buf string
+	if !streaming {
+		buf, err = req.encode(head)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	hasBody := len(req.bodies()) > 0
+
+	if req.Compress != "" && hasBody && !raw {
+		buf, err = compress(req.Compress, buf)
+		if err != nil {
+			return nil, "", err
+		}
+		head["Content-Encoding"] = strings.ToLower(req.Compress)
+	}
+
+	switch {
+	case streaming:
+		bodyReader = stream
+	case hasBody:
+		bodyReader = strings.NewReader(buf)
+		head["Content-Length"] = strconv.Itoa(len(buf))
+	}
+
+	switch method {
+	case "PUT", "PATCH", "DELETE":
+		if req.MethodOverride {
+			head["X-HTTP-Method-Override"] = method
+			method = "POST"
+		}
+	}
+
+	httpreq, err := http.NewRequest(method, u, bodyReader)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if streaming {
+		if err := rewind(httpreq, stream); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if raw {
+		httpreq.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(struct{ io.Reader }{bytes.NewReader(req.RawBody)}), nil
+		}
+	}
+
+	// net/http ignores Host in the header map and uses httpreq.Host
+	for k, v := range head {
+		if strings.EqualFold(k, "Host") {
+			httpreq.Host = v
+			continue
+		}
+		httpreq.Header.Add(k, v)
+	}
+
+	if req.Host != "" {
+		httpreq.Host = req.Host
+	}
+
+	if req.Range != "" {
+		httpreq.Header.Set("Range", req.Range)
+	}
+
+	if req.IfMatch != "" {
+		httpreq.Header.Set("If-Match", req.IfMatch)
+	}
+
+	return httpreq, buf, nil
+}
+
+// SubmitRequest is the same as Submit but sends the already built
+// httpreq as is instead of one from the U, M, Q, H, and B (and such)
+// of Req, which are ignored. Everything else about the handling of the
+// response (D, OnStatus, IsError, R, Timings) remains the same. The
+// context of httpreq is only replaced when Req.C is set but the
+// web.TimeOut still applies.
+func (req *Req) SubmitRequest(httpreq *http.Request) error {
+	if httpreq == nil || httpreq.URL == nil {
+		return ReqSyntaxError{"missing request"}
+	}
+	var buf string
+	if httpreq.GetBody != nil {
+		if body, err := httpreq.GetBody(); err == nil {
+			byt, _ := io.ReadAll(body)
+			body.Close()
+			buf = string(byt)
+		}
+	}
+	return req.send(httpreq, buf, false)
+}
+
+// send does the work of Submit once the httpreq has been built from
+// the Req (buf being the text of its body for the HAR). When open it
+// returns as soon as the response has arrived and leaves everything
+// that reading its body needs until it is closed (see Do).
+func (req *Req) send(httpreq *http.Request, buf string, open bool) (err error) {
+
+	// released (in reverse) once done with the response
+	var held []func()
+	release := func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i]()
+		}
+	}
+	defer func() {
+		if !open || err != nil {
+			release()
+		}
+	}()
+
+	if req.C == nil {
+		dur := time.Duration(time.Second * time.Duration(TimeOut))
+		ctx, cancel := context.WithTimeout(httpreq.Context(), dur)
+		held = append(held, cancel)
+		httpreq = httpreq.WithContext(ctx)
+	} else {
+		httpreq = httpreq.WithContext(req.C)
+	}
+
+	if req.MaxTotalTime > 0 {
+		ctx, cancel := context.WithTimeout(httpreq.Context(), req.MaxTotalTime)
+		held = append(held, cancel)
+		httpreq = httpreq.WithContext(ctx)
+		defer func() {
+			if err != nil && ctx.Err() == context.DeadlineExceeded {
+				err = TotalTimeError{req.MaxTotalTime, err}
+			}
+		}()
+	}
+
+	client := Client
+	var budget *RetryBudget
+	if req.S != nil {
+		if err := req.S.prepare(httpreq); err != nil {
+			return err
+		}
+		client = req.S.Client()
+		budget = req.S.RetryBudget
+		if req.S.RequestID {
+			req.RequestID = httpreq.Header.Get(req.S.requestIDHeader())
+		}
+	}
+
+	if req.ClientCert != nil {
+		if req.S != nil {
+			client = req.S.certClient(req.ClientCert)
+		} else if client, err = withCert(client, req.ClientCert); err != nil {
+			return err
+		}
+	}
+
+	client = loops(client)
+
+	if req.TraceRedirects {
+		req.RedirectChain = nil
+		client = req.trace(client)
+	}
+
+	if req.Dump {
+		if req.RawRequest, err = httputil.DumpRequestOut(httpreq, true); err != nil {
+			return err
+		}
+	}
+
+	if req.TraceTimings {
+		var tm *timer
+		httpreq, tm = traced(httpreq, &req.Timings)
+		held = append(held, tm.done)
+	}
+
+	start := time.Now()
+	res, err := do(client, httpreq, req.RetryOn, budget)
+	req.R = res
+
+	if err != nil {
+		return netError(err)
+	}
+	defer func() {
+		if !open || err != nil {
+			res.Body.Close()
+		}
+	}()
+	req.SetCookies = res.Cookies()
+	req.Partial = partial(res)
+
+	if req.Dump {
+		if req.RawResponse, err = httputil.DumpResponse(res, true); err != nil {
+			return err
+		}
+	}
+
+	if req.TraceRedirects {
+		req.RedirectChain = append(req.RedirectChain,
+			Hop{URL: res.Request.URL.String(), Status: res.StatusCode})
+	}
+
+	if open {
+		if err := decompress(res, req.maxBody()); err != nil {
+			return err
+		}
+		res.Body = released{res.Body, release}
+		return nil
+	}
+
+	if !(200 <= res.StatusCode && res.StatusCode < 300) {
+		_, target := req.OnStatus[res.StatusCode]
+		if target {
+			decompress(res, req.maxBody())
+		}
+		drain(res)
+		if err := req.archive(start, httpreq, buf, res, nil); err != nil {
+			return err
+		}
+		if target {
+			byt, _ := io.ReadAll(res.Body)
+			res.Body = io.NopCloser(bytes.NewReader(byt))
+			req.decodeStatus(res.StatusCode, res.Header.Get("Content-Type"), byt)
+		}
+		if res.StatusCode == http.StatusPreconditionFailed {
+			return PreconditionFailedError{res}
+		}
+		return HTTPError{res}
+	}
+
+	if err := decompress(res, req.maxBody()); err != nil {
+		return err
+	}
+
+	// streamed bodies are never buffered (so archived without one)
+	if each, is := req.D.(func(string) error); is {
+		if err := lines(httpreq.Context(), res.Body, each); err != nil {
+			return err
+		}
+		req.Trailer = res.Trailer
+		return req.archive(start, httpreq, buf, res, []byte{})
+	}
+
+	if w, is := req.D.(io.Writer); is && !req.indents(res.Header.Get("Content-Type")) {
+		if _, err := io.Copy(w, res.Body); err != nil {
+			return err
+		}
+		req.Trailer = res.Trailer
+		return req.archive(start, httpreq, buf, res, []byte{})
+	}
+
+	resbytes, err := readBody(res.Body, req.maxBody())
+	if err != nil {
+		return err
+	}
+	req.Trailer = res.Trailer
+	req.body = resbytes
+
+	if err := req.archive(start, httpreq, buf, res, resbytes); err != nil {
+		return err
+	}
+
+	if req.IsError != nil {
+		if err := req.IsError(res, resbytes); err != nil {
+			return err
+		}
+	}
+
+	if len(req.Schema) > 0 {
+		if err := validate(req.Schema, resbytes); err != nil {
+			return err
+		}
+	}
+
+	if len(resbytes) == 0 {
+		return nil
+	}
+
+	return req.decodeStatus(res.StatusCode, res.Header.Get("Content-Type"), resbytes)
+
+}
+
+// released is a response body that also releases everything held for
+// it once closed (see Do).
+type released struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close fulfills the io.Closer interface.
+func (r released) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
+
+// archive adds the request and response to the HAR of the Session (if
+// recording). A nil resbody is read from the (drained) response.
+func (req *Req) archive(
+	start time.Time, r *http.Request, body string,
+	res *http.Response, resbody []byte,
+) error {
+	if req.S == nil || req.S.har == nil {
+		return nil
+	}
+	if resbody == nil {
+		resbody, _ = io.ReadAll(res.Body)
+		res.Body = io.NopCloser(bytes.NewReader(resbody))
+	}
+	return req.S.har.add(start, r, body, res, resbody)
+}
+
+// Validate returns a ReqSyntaxError for the first problem found with
+// the Req fields that would prevent it from being submitted without
+// sending anything. Submit calls Validate first.
+func (req *Req) Validate() error {
+	if req.U == "" {
+		return ReqSyntaxError{"missing URL (U)"}
+	}
+	u, err := url.Parse(req.U)
+	if err != nil {
+		return ReqSyntaxError{err.Error()}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ReqSyntaxError{"unsupported URL scheme: " + req.U}
+	}
+	if u.Host == "" {
+		return ReqSyntaxError{"missing URL host: " + req.U}
+	}
+	if req.Q != nil && strings.Contains(req.U, "?") {
+		return ReqSyntaxError{"query string in both URL (U) and Q"}
+	}
+	if req.Params != nil && strings.Contains(req.U, "?") {
+		return ReqSyntaxError{"query string in both URL (U) and Params"}
+	}
+	if req.RawQuery != "" && strings.Contains(req.U, "?") {
+		return ReqSyntaxError{"query string in both URL (U) and RawQuery"}
+	}
+	if strings.ContainsAny(req.M, " \t\r\n") {
+		return ReqSyntaxError{"invalid method (M): " + req.M}
+	}
+	if sources := req.bodies(); len(sources) > 1 {
+		return ReqSyntaxError{
+			"conflicting body sources: " + strings.Join(sources, ", "),
+		}
+	}
+	switch strings.ToLower(req.Compress) {
+	case "", "gzip", "zstd":
+	default:
+		return ReqSyntaxError{"unsupported compression: " + req.Compress}
+	}
+	if req.IsError != nil || len(req.Schema) > 0 {
+		switch req.D.(type) {
+		case io.Writer, func(string) error:
+			return ReqSyntaxError{"IsError and Schema need a buffered D, not streamed"}
+		}
+	}
+	return nil
+}
+
+// bodies returns the names of the fields set that are each a source of
+// the request body, only one of which is allowed.
+func (req *Req) bodies() []string {
+	var names []string
+	if req.B != nil {
+		names = append(names, "B")
+	}
+	if req.BodyTemplate != "" {
+		names = append(names, "BodyTemplate")
+	}
+	if req.RawBody != nil {
+		names = append(names, "RawBody")
+	}
+	return names
+}
+
+// drain reads up to DrainLimit bytes of the response body and replaces
+// it with a buffered copy so that the original can be closed (and the
+// connection reused) without losing the content for the caller.
+func drain(res *http.Response) {
+	byt, _ := io.ReadAll(io.LimitReader(res.Body, DrainLimit))
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(byt))
+}
+
+// encode returns the body (B) encoded according to the Content-Type
+// header hint (if any) in head falling back on the type of B itself
+// (which may set the Content-Type of head).
+func (req *Req) encode(head Head) (string, error) {
+
+	if req.BodyTemplate != "" {
+		return req.render()
+	}
+
+	var marshal func(any) ([]byte, error)
+
+	switch mediatype(head["Content-Type"]) {
+	case "application/msgpack", "application/x-msgpack":
+		marshal = msgpack.Marshal
+	case "application/cbor":
+		marshal = cbor.Marshal
+	case "application/toml":
+		marshal = tomlMarshal
+	case "text/csv":
+		marshal = func(v any) ([]byte, error) { return encodeCSV(v, req.Comma) }
+	}
+
+	if marshal != nil {
+		byt, err := marshal(req.B)
+		if err != nil {
+			return "", err
+		}
+		return string(byt), nil
 	}
 
 	var buf string
 
 	switch v := req.B.(type) {
+	case nil:
+		// no body at all
+	case GraphQL:
+		byt, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		buf = string(byt)
+		head["Content-Type"] = "application/json"
 	case url.Values:
 		buf = v.Encode()
-		req.H["Content-Type"] = "application/x-www-form-urlencoded"
+		head["Content-Type"] = "application/x-www-form-urlencoded"
 	case []byte:
 		log.Println("planned, but unimplemented, would uuencode")
-		//req.H["Content-Length"] = strconv.Itoa(len(uuencoded))
+		//head["Content-Length"] = strconv.Itoa(len(uuencoded))
 	case string:
 		buf = v
+	case Multipart:
+		var out strings.Builder
+		body := v.body()
+		if err := v.write(&out, body.boundary); err != nil {
+			return "", err
+		}
+		buf = out.String()
+		head["Content-Type"] = body.contentType()
+	case NDJSON:
+		byt, err := io.ReadAll(v.body())
+		if err != nil {
+			return "", err
+		}
+		buf = string(byt)
+		head["Content-Type"] = "application/x-ndjson"
+	case io.Reader:
+		byt, err := io.ReadAll(v)
+		if err != nil {
+			return "", err
+		}
+		buf = string(byt)
 	case yaml.Marshaler:
 		byt, err := yaml.Marshal(v)
 		if err != nil {
-			return err
+			return "", err
 		}
 		buf = string(byt)
 	case json.Marshaler:
 		byt, err := json.Marshal(v)
 		if err != nil {
-			return err
+			return "", err
+		}
+		buf = string(byt)
+	case toml.Marshaler:
+		byt, err := v.MarshalTOML()
+		if err != nil {
+			return "", err
 		}
 		buf = string(byt)
 	case encoding.TextMarshaler:
 		byt, err := v.MarshalText()
 		if err != nil {
-			return err
+			return "", err
 		}
 		buf = string(byt)
 	case fmt.Stringer:
 		buf = v.String()
 	default:
-		buf = fmt.Sprintf("%v", v)
+		var byt []byte
+		var err error
+		switch ctype := value(head, "Content-Type"); {
+		case csvSlice(reflect.TypeOf(v)) && ctype == "":
+			byt, err = encodeCSV(v, req.Comma)
+			head["Content-Type"] = "text/csv"
+		case csvSlice(reflect.TypeOf(v)) && mediatype(ctype) == "text/csv":
+			byt, err = encodeCSV(v, req.Comma)
+		case syntax(ctype) == "json":
+			byt, err = json.Marshal(v)
+		case syntax(ctype) == "yaml":
+			byt, err = yaml.Marshal(v)
+		default:
+			byt = []byte(fmt.Sprintf("%v", v))
+		}
+		if err != nil {
+			return "", err
+		}
+		buf = string(byt)
 	}
 
-	bodyReader = strings.NewReader(buf)
-	req.H["Content-Length"] = strconv.Itoa(len(buf))
-
-	httpreq, err := http.NewRequest(req.M, req.U, bodyReader)
-	if err != nil {
-		return err
-	}
+	return buf, nil
+}
 
-	if req.H != nil {
-		for k, v := range req.H {
-			httpreq.Header.Add(k, v)
+// decodeStatus decodes the response bytes into the OnStatus target for
+// the status code (replacing it) or else into D (if not nil).
+func (req *Req) decodeStatus(code int, ctype string, byt []byte) error {
+	target, has := req.OnStatus[code]
+	if !has {
+		if req.D == nil {
+			return nil
 		}
+		return req.decode(ctype, byt)
 	}
-
-	if req.C == nil {
-		dur := time.Duration(time.Second * time.Duration(TimeOut))
-		ctx, cancel := context.WithTimeout(context.Background(), dur)
-		defer cancel()
-		httpreq = httpreq.WithContext(ctx)
-	}
-
-	res, err := Client.Do(httpreq)
-	req.R = res
-
-	if err != nil {
-		return err
+	if target == nil {
+		return nil
 	}
+	d := req.D
+	req.D = target
+	err := req.decode(ctype, byt)
+	req.OnStatus[code], req.D = req.D, d
+	return err
+}
 
-	if !(200 <= res.StatusCode && res.StatusCode < 300) {
-		return HTTPError{res}
-	}
+// decode populates the data (D) from the response bytes according to
+// the response Content-Type (if recognized) falling back on the type
+// of D itself.
+func (req *Req) decode(ctype string, resbytes []byte) error {
 
-	resbytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
+	switch mediatype(ctype) {
+	case "application/msgpack", "application/x-msgpack":
+		return unmarshal(msgpack.Unmarshal, resbytes, req.D)
+	case "application/cbor":
+		return unmarshal(cbor.Unmarshal, resbytes, req.D)
+	case "application/toml":
+		return unmarshal(toml.Unmarshal, resbytes, req.D)
+	case "text/csv":
+		if _, is := csvTarget(req.D); is {
+			return decodeCSV(resbytes, req.D, req.Comma)
+		}
 	}
 
-	if len(resbytes) == 0 {
-		return nil
+	switch v := req.D.(type) {
+	case *GraphQLResult:
+		return v.decode(resbytes)
+	case string, []byte, io.Writer:
+		// never decoded as JSON
+	default:
+		if req.StrictJSON {
+			return unmarshal(strictJSON, resbytes, req.D)
+		}
+		switch rv := reflect.ValueOf(req.D); {
+		case rv.Kind() == reflect.Slice:
+			return ReqSyntaxError{fmt.Sprintf("D must be a pointer to a slice: %T", req.D)}
+		case rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Slice:
+			if syntax(ctype) == "json" {
+				return json.Unmarshal(resbytes, req.D)
+			}
+		}
 	}
 
-	switch req.D.(type) {
+	switch v := req.D.(type) {
 	case map[string]any:
 		return yaml.Unmarshal(resbytes, req.D)
 	case string:
-		req.D = string(resbytes)
+		req.D = string(req.indent(ctype, transcode(ctype, resbytes)))
 	case []byte:
 		log.Println("planned, but unimplemented, would uuencode")
 		// v = uudecode(resbytes)
 	case yaml.Unmarshaler:
 		return yaml.Unmarshal(resbytes, req.D)
 	case io.Writer:
-		return nil
+		_, err := v.Write(req.indent(ctype, resbytes))
+		return err
 	case rwxjson.This:
 		log.Println("rwxjson, planned, but unimplemented")
 	default:
@@ -221,5 +990,57 @@ func (req *Req) Submit() error {
 	}
 
 	return nil
+}
+
+// unmarshal calls fn to populate data from byt. Since most decoders
+// replace rather than fill a map passed by value, a map[string]any
+// is populated from a decoded copy so that the caller's map is
+// updated instead.
+func unmarshal(fn func([]byte, any) error, byt []byte, data any) error {
+	m, is := data.(map[string]any)
+	if !is {
+		return fn(byt, data)
+	}
+	v := map[string]any{}
+	if err := fn(byt, &v); err != nil {
+		return err
+	}
+	for k, val := range v {
+		m[k] = val
+	}
+	return nil
+}
 
+// strictJSON unmarshals the JSON byt into data allowing nothing but
+// the fields of data and keeping numbers as json.Number (see StrictJSON).
+func strictJSON(byt []byte, data any) error {
+	dec := json.NewDecoder(bytes.NewReader(byt))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(data); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after top-level JSON value")
+	}
+	return nil
+}
+
+// tomlMarshal provides the missing toml.Marshal function.
+func tomlMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mediatype returns the lowercase media type of the Content-Type value
+// without any parameters (charset, boundary, etc.).
+func mediatype(ctype string) string {
+	mt, _, err := mime.ParseMediaType(ctype)
+	if err != nil {
+		return ""
+	}
+	return mt
 }
@@ -1,14 +1,22 @@
 package web_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	ht "net/http/httptest"
+	"net/url"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
 
 	web "github.com/rwxrob/web"
 )
 
-func ExampleFetch_get() {
+func ExampleReq_Submit_get() {
 
 	// serve get
 	handler := http.HandlerFunc(
@@ -34,7 +42,7 @@ func ExampleFetch_get() {
 	// WORKED
 }
 
-func ExampleFetch_post() {
+func ExampleReq_Submit_post() {
 
 	// serve post
 	handler := http.HandlerFunc(
@@ -59,3 +67,594 @@ func ExampleFetch_post() {
 	// 200 OK
 	// WORKED
 }
+
+func ExampleReq_Submit_msgpack() {
+
+	// serve msgpack echo
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			in := map[string]any{}
+			msgpack.NewDecoder(r.Body).Decode(&in)
+			w.Header().Set("Content-Type", "application/msgpack")
+			msgpack.NewEncoder(w).Encode(map[string]any{"got": in["sent"]})
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+
+	req := &web.Req{
+		M: `POST`,
+		U: svr.URL,
+		H: web.Head{"Content-Type": "application/msgpack"},
+		B: struct {
+			Sent string `msgpack:"sent"`
+		}{"WORKED"},
+		D: data,
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["got"])
+
+	// Output:
+	// WORKED
+}
+
+func ExampleReq_Submit_cbor() {
+
+	// serve cbor echo
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			in := map[string]any{}
+			cbor.NewDecoder(r.Body).Decode(&in)
+			w.Header().Set("Content-Type", "application/cbor")
+			cbor.NewEncoder(w).Encode(map[string]any{"got": in["sent"]})
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+
+	req := &web.Req{
+		M: `POST`,
+		U: svr.URL,
+		H: web.Head{"Content-Type": "application/cbor"},
+		B: struct {
+			Sent string `cbor:"sent"`
+		}{"WORKED"},
+		D: data,
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["got"])
+
+	// Output:
+	// WORKED
+}
+
+func ExampleReq_Submit_toml() {
+
+	// serve toml echo
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			in := map[string]any{}
+			toml.NewDecoder(r.Body).Decode(&in)
+			w.Header().Set("Content-Type", "application/toml")
+			toml.NewEncoder(w).Encode(map[string]any{"got": in["sent"]})
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+
+	req := &web.Req{
+		M: `POST`,
+		U: svr.URL,
+		H: web.Head{"Content-Type": "application/toml"},
+		B: map[string]any{"sent": "WORKED"},
+		D: data,
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["got"])
+
+	// Output:
+	// WORKED
+}
+
+func ExampleReq_Submit_host() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"host":%q}`, r.Host)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+
+	req := &web.Req{U: svr.URL, D: data, Host: "canary.example.com"}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["host"])
+
+	// Output:
+	// canary.example.com
+}
+
+func ExampleReq_Submit_host_header() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"host":%q}`, r.Host)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+
+	req := &web.Req{U: svr.URL, D: data, H: web.Head{"Host": "example.com"}}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["host"])
+
+	// Output:
+	// example.com
+}
+
+func ExampleHTTPError() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintf(w, `slow down`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL}
+	err := req.Submit()
+	fmt.Println(err)
+
+	herr, _ := err.(web.HTTPError)
+	body, _ := io.ReadAll(herr.Resp.Body)
+	fmt.Println(string(body))
+
+	// Output:
+	// 429 Too Many Requests
+	// slow down
+}
+
+func ExampleReq_Submit_trailer() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Trailer", "Grpc-Status")
+			fmt.Fprintf(w, `{"get":"WORKED"}`)
+			w.Header().Set("Grpc-Status", "0")
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, D: map[string]any{}}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.Trailer.Get("Grpc-Status"))
+
+	// also once streamed to an io.Writer
+	req = &web.Req{U: svr.URL, D: io.Discard}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.Trailer.Get("Grpc-Status"))
+
+	// Output:
+	// 0
+	// 0
+}
+
+func ExampleReq_Validate() {
+	fmt.Println((&web.Req{U: `https://example.com`}).Validate())
+	fmt.Println((&web.Req{U: `example.com`}).Validate())
+	fmt.Println((&web.Req{
+		U: `https://example.com?a=1`,
+		Q: url.Values{"b": {"2"}},
+	}).Validate())
+	fmt.Println((&web.Req{U: `https://example.com`, Compress: `lz4`}).Validate())
+	fmt.Println((&web.Req{
+		U:            `https://example.com`,
+		B:            `body`,
+		BodyTemplate: `{{.body}}`,
+	}).Validate())
+	fmt.Println((&web.Req{
+		U:       `https://example.com`,
+		B:       `body`,
+		RawBody: []byte(`body`),
+	}).Validate())
+	fmt.Println((&web.Req{
+		U:      `https://example.com`,
+		D:      io.Discard,
+		Schema: []byte(`{"type":"object"}`),
+	}).Validate())
+	// Output:
+	// <nil>
+	// unsupported URL scheme: example.com
+	// query string in both URL (U) and Q
+	// unsupported compression: lz4
+	// conflicting body sources: B, BodyTemplate
+	// conflicting body sources: B, RawBody
+	// IsError and Schema need a buffered D, not streamed
+}
+
+func ExampleReq_Submit_head() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Method", r.Method)
+			fmt.Fprintf(w, `{"get":"WORKED"}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{M: `HEAD`, U: svr.URL, D: ``}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%v %q\n", req.R.Header.Get("X-Method"), req.D)
+
+	// Output:
+	// HEAD ""
+}
+
+func ExampleReq_Submit_patch_nil() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			byt, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%v %q", r.Method, byt)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{M: `PATCH`, U: svr.URL, D: ``}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// Output:
+	// PATCH ""
+}
+
+func ExampleReq_Submit_get_nil() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			byt, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%q %q", r.Header.Values("Content-Length"), byt)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, D: ``}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// Output:
+	// [] ""
+}
+
+func ExampleReq_Submit_isError() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"error":"quota exceeded"}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{
+		U: svr.URL,
+		D: map[string]any{},
+		IsError: func(res *http.Response, body []byte) error {
+			var v struct{ Error string }
+			if json.Unmarshal(body, &v) == nil && v.Error != "" {
+				return errors.New(v.Error)
+			}
+			return nil
+		},
+	}
+	fmt.Println(req.Submit(), req.R.StatusCode)
+
+	// Output:
+	// quota exceeded 200
+}
+
+func ExampleReq_Submit_onStatus() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("id") != "1" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"code":"E404","message":"no such user"}`)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"name":"rwxrob"}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type User struct{ Name string }
+	type Problem struct{ Code, Message string }
+
+	for _, id := range []string{"1", "2"} {
+		user, problem := &User{}, &Problem{}
+		req := &web.Req{
+			U:        svr.URL,
+			Q:        url.Values{"id": {id}},
+			OnStatus: map[int]any{201: user, 404: problem},
+		}
+		err := req.Submit()
+		fmt.Println(err, *user, *problem)
+	}
+
+	// Output:
+	// <nil> {rwxrob} { }
+	// 404 Not Found {} {E404 no such user}
+}
+
+func ExampleReq_Submit_setCookies() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", HttpOnly: true})
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark"})
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL}
+	fmt.Println(req.Submit())
+	for _, c := range req.SetCookies {
+		fmt.Println(c.Name, c.Value, c.HttpOnly)
+	}
+
+	// Output:
+	// 401 Unauthorized
+	// session abc true
+	// theme dark false
+}
+
+func ExampleReq_SubmitRequest() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"method":%q,"token":%q}`,
+				r.Method, r.Header.Get("X-Token"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	httpreq, _ := http.NewRequest("DELETE", svr.URL, nil)
+	httpreq.Header.Set("X-Token", "secret")
+
+	data := map[string]any{}
+	req := &web.Req{D: data}
+	if err := req.SubmitRequest(httpreq); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["method"], data["token"], req.R.StatusCode)
+
+	// Output:
+	// DELETE secret 200
+}
+
+func ExampleReq_Submit_strictJSON() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, `{"name":"doe","id":12345678901234567890,"extra":true}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]any{}
+	req := &web.Req{U: svr.URL, D: data, StrictJSON: true}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%T %v\n", data["id"], data["id"])
+
+	type User struct {
+		Name string      `json:"name"`
+		ID   json.Number `json:"id"`
+	}
+	user := new(User)
+	req = &web.Req{U: svr.URL, D: user, StrictJSON: true}
+	fmt.Println(req.Submit())
+
+	// Output:
+	// json.Number 12345678901234567890
+	// json: unknown field "extra"
+}
+
+func ExampleReq_Submit_methodOverride() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%v %q", r.Method, r.Header.Get("X-HTTP-Method-Override"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	for _, m := range []string{"delete", "GET"} {
+		req := &web.Req{U: svr.URL, M: m, D: ``, MethodOverride: true}
+		if err := req.Submit(); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(req.M, req.D)
+	}
+
+	// Output:
+	// delete POST "DELETE"
+	// GET GET ""
+}
+
+func ExamplePreconditionFailedError() {
+
+	etag := `"v2"`
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				w.Header().Set("ETag", etag)
+				fmt.Fprint(w, `{"n":2}`)
+				return
+			}
+			if r.Header.Get("If-Match") != etag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			etag = `"v3"`
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, M: "PUT", B: `{"n":3}`, IfMatch: `"v1"`}
+	err := req.Submit()
+	fmt.Println(err)
+	fmt.Println(errors.As(err, new(web.PreconditionFailedError)))
+	fmt.Println(errors.As(err, new(web.HTTPError)))
+
+	// read again, then write with the current ETag
+	get := &web.Req{U: svr.URL}
+	get.Submit()
+	req = &web.Req{U: svr.URL, M: "PUT", B: `{"n":3}`, IfMatch: get.R.Header.Get("ETag")}
+	fmt.Println(req.Submit(), etag)
+
+	// Output:
+	// 412 Precondition Failed
+	// true
+	// true
+	// <nil> "v3"
+}
+
+func ExampleReq_Submit_rawBody() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			byt, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%v %v %q %q", r.ContentLength, r.TransferEncoding,
+				r.Header.Get("Content-Type"), byt)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{
+		U: svr.URL, M: "POST", D: ``,
+		RawBody:  []byte("\x00{not json"),
+		Compress: "gzip",
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(req.D)
+
+	// Output:
+	// -1 [chunked] "" "\x00{not json"
+}
+
+func ExampleReq_Submit_slice() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[{"name":"a","user_id":1},{"name":"b","user_id":2}]`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	type User struct {
+		Name string `json:"name"`
+		ID   int    `json:"user_id"`
+	}
+	var users []User
+	fmt.Println((&web.Req{U: svr.URL, D: &users}).Submit(), users)
+
+	var maps []map[string]any
+	fmt.Println((&web.Req{U: svr.URL, D: &maps}).Submit(), maps[1]["name"])
+
+	var all []any
+	fmt.Println((&web.Req{U: svr.URL, D: &all}).Submit(), len(all))
+
+	fmt.Println((&web.Req{U: svr.URL, D: []map[string]any{}}).Submit())
+
+	// Output:
+	// <nil> [{a 1} {b 2}]
+	// <nil> b
+	// <nil> 2
+	// D must be a pointer to a slice: []map[string]interface {}
+}
+
+func ExampleReq_Submit_dump() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header()["Date"] = nil
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, "pong")
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{
+		U:    svr.URL + "/ping",
+		M:    "POST",
+		B:    "ping",
+		H:    web.Head{"Content-Type": "text/plain"},
+		Host: "example.com",
+		Dump: true,
+	}
+	if err := req.Submit(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%q\n", req.RawRequest)
+	fmt.Printf("%q\n", req.RawResponse)
+
+	// Output:
+	// "POST /ping HTTP/1.1\r\nHost: example.com\r\nUser-Agent: Go-http-client/1.1\r\nContent-Length: 4\r\nContent-Type: text/plain\r\nAccept-Encoding: gzip\r\n\r\nping"
+	// "HTTP/1.1 200 OK\r\nContent-Length: 4\r\nContent-Type: text/plain\r\n\r\npong"
+}
+
+func ExampleReq_Do() {
+
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			fmt.Fprint(w, r.URL.Query().Get("brew"))
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	req := &web.Req{U: svr.URL, Q: url.Values{"brew": {"tea"}}}
+	res, err := req.Do()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	fmt.Println(res.StatusCode, string(body), req.R == res)
+
+	// Output:
+	// 418 tea true
+}